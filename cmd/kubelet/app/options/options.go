@@ -532,6 +532,8 @@ func AddKubeletConfigFlags(mainfs *pflag.FlagSet, c *kubeletconfig.KubeletConfig
 	fs.BoolVar(&c.RTHCBS, "rt-hcbs", c.RTHCBS, "Enables realtime hCBS scheduling of containers")
 	fs.DurationVar(&c.RTPeriod.Duration, "rt-period", c.RTPeriod.Duration, "Sets the the CPU period value, cpu.rt_perios_us")
 	fs.DurationVar(&c.RTRuntime.Duration, "rt-runtime", c.RTRuntime.Duration, "Sets the the CPU runtime value, cpu.rt_runtime_us")
+	fs.BoolVar(&c.RTAggressiveReclaim, "rt-aggressive-reclaim", c.RTAggressiveReclaim, "Makes the cpu manager reclaim the RT utilization of containers gone from the runtime even while other pods' statuses are incomplete")
+	fs.BoolVar(&c.RTSerializeStartup, "rt-serialize-startup", c.RTSerializeStartup, "Makes the cpu manager serialize concurrent RT container starts ordered by descending RT utilization, minimizing cpu fragmentation after a reboot")
 	fs.BoolVar(&c.EnableControllerAttachDetach, "enable-controller-attach-detach", c.EnableControllerAttachDetach, "Enables the Attach/Detach controller to manage attachment/detachment of volumes scheduled to this node, and disables kubelet from executing any attach/detach operations")
 	fs.BoolVar(&c.MakeIPTablesUtilChains, "make-iptables-util-chains", c.MakeIPTablesUtilChains, "If true, kubelet will ensure iptables utility rules are present on host.")
 	fs.Int32Var(&c.IPTablesMasqueradeBit, "iptables-masquerade-bit", c.IPTablesMasqueradeBit, "The bit of the fwmark space to mark packets for SNAT. Must be within the range [0, 31]. Please match this parameter with corresponding parameter in kube-proxy.")
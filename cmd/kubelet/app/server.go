@@ -738,6 +738,8 @@ func run(s *options.KubeletServer, kubeDeps *kubelet.Dependencies, featureGate f
 				EnforceRealTime:                       s.RTHCBS,
 				CpuRtPeriod:                           s.RTPeriod.Duration,
 				CpuRtRuntime:                          s.RTRuntime.Duration,
+				CpuRtAggressiveReclaim:                s.RTAggressiveReclaim,
+				CpuRtSerializeStartup:                 s.RTSerializeStartup,
 				ExperimentalTopologyManagerPolicy:     s.TopologyManagerPolicy,
 			},
 			s.FailSwapOn,
@@ -77,8 +77,11 @@ func GetPodQOS(pod *core.Pod) core.PodQOSClass {
 			}
 		}
 
+		// An RT runtime limit counts only when the RT period accompanies it:
+		// a runtime without a period is an incomplete RT spec and carries no
+		// guarantee.
 		if !qosLimitsFound.HasAll(string(core.ResourceMemory), string(core.ResourceCPU)) &&
-			!qosLimitsFound.Has(string(core.ResourceRtRuntime)) {
+			!qosLimitsFound.HasAll(string(core.ResourceRtRuntime), string(core.ResourceRtPeriod)) {
 			isGuaranteed = false
 		}
 	}
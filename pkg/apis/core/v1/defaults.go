@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/kubernetes/pkg/util/parsers"
@@ -160,6 +161,28 @@ func SetDefaults_Service(obj *v1.Service) {
 	}
 
 }
+// defaultCpuRtPeriodUs is the RT period applied to containers that request an
+// RT runtime without choosing a period, the kernel's default
+// sched_rt_period_us.
+const defaultCpuRtPeriodUs = 1000000
+
+// setDefaults_RtResources completes a partial real-time request: a container
+// asking for an RT runtime gets the default period and a single RT cpu unless
+// it chose its own. Containers without an RT runtime are left alone, so an
+// rt_cpu or rt_period request on its own stays visible to validation instead
+// of silently gaining a reservation.
+func setDefaults_RtResources(requests v1.ResourceList) {
+	if requests.CpuRtRuntime().IsZero() {
+		return
+	}
+	if _, exists := requests[v1.ResourceRtPeriod]; !exists {
+		requests[v1.ResourceRtPeriod] = *resource.NewQuantity(defaultCpuRtPeriodUs, resource.DecimalSI)
+	}
+	if _, exists := requests[v1.ResourceRtCpu]; !exists {
+		requests[v1.ResourceRtCpu] = *resource.NewQuantity(1, resource.DecimalSI)
+	}
+}
+
 func SetDefaults_Pod(obj *v1.Pod) {
 	// If limits are specified, but requests are not, default requests to limits
 	// This is done here rather than a more specific defaulting pass on v1.ResourceRequirements
@@ -176,6 +199,7 @@ func SetDefaults_Pod(obj *v1.Pod) {
 				}
 			}
 		}
+		setDefaults_RtResources(obj.Spec.Containers[i].Resources.Requests)
 	}
 	for i := range obj.Spec.InitContainers {
 		if obj.Spec.InitContainers[i].Resources.Limits != nil {
@@ -188,6 +212,7 @@ func SetDefaults_Pod(obj *v1.Pod) {
 				}
 			}
 		}
+		setDefaults_RtResources(obj.Spec.InitContainers[i].Resources.Requests)
 	}
 	if obj.Spec.EnableServiceLinks == nil {
 		enableServiceLinks := v1.DefaultEnableServiceLinks
@@ -1740,6 +1740,70 @@ func TestSetDefaultRequestsPod(t *testing.T) {
 	}
 }
 
+func TestSetDefaultRtRequestsPod(t *testing.T) {
+	podWithRequests := func(requests v1.ResourceList) *v1.Pod {
+		return &v1.Pod{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Resources: v1.ResourceRequirements{Requests: requests}},
+				},
+			},
+		}
+	}
+
+	// a runtime on its own gets the default period and a single RT cpu
+	pod := podWithRequests(v1.ResourceList{
+		v1.ResourceRtRuntime: resource.MustParse("100000"),
+	})
+	output := roundTrip(t, runtime.Object(pod))
+	defaulted := output.(*v1.Pod).Spec.Containers[0].Resources.Requests
+	if period := defaulted[v1.ResourceRtPeriod]; period.Value() != 1000000 {
+		t.Errorf("Expected default rt_period 1000000, got: %s", period.String())
+	}
+	if cpus := defaulted[v1.ResourceRtCpu]; cpus.Value() != 1 {
+		t.Errorf("Expected default rt_cpu 1, got: %s", cpus.String())
+	}
+
+	// a chosen period survives, only the cpu count is completed
+	pod = podWithRequests(v1.ResourceList{
+		v1.ResourceRtRuntime: resource.MustParse("100000"),
+		v1.ResourceRtPeriod:  resource.MustParse("500000"),
+	})
+	output = roundTrip(t, runtime.Object(pod))
+	defaulted = output.(*v1.Pod).Spec.Containers[0].Resources.Requests
+	if period := defaulted[v1.ResourceRtPeriod]; period.Value() != 500000 {
+		t.Errorf("Expected rt_period 500000, got: %s", period.String())
+	}
+	if cpus := defaulted[v1.ResourceRtCpu]; cpus.Value() != 1 {
+		t.Errorf("Expected default rt_cpu 1, got: %s", cpus.String())
+	}
+
+	// a fully specified request passes through untouched
+	pod = podWithRequests(v1.ResourceList{
+		v1.ResourceRtRuntime: resource.MustParse("100000"),
+		v1.ResourceRtPeriod:  resource.MustParse("500000"),
+		v1.ResourceRtCpu:     resource.MustParse("3"),
+	})
+	output = roundTrip(t, runtime.Object(pod))
+	defaulted = output.(*v1.Pod).Spec.Containers[0].Resources.Requests
+	if cpus := defaulted[v1.ResourceRtCpu]; cpus.Value() != 3 {
+		t.Errorf("Expected rt_cpu 3, got: %s", cpus.String())
+	}
+
+	// rt_cpu without a runtime gains nothing, validation rejects it instead
+	pod = podWithRequests(v1.ResourceList{
+		v1.ResourceRtCpu: resource.MustParse("2"),
+	})
+	output = roundTrip(t, runtime.Object(pod))
+	defaulted = output.(*v1.Pod).Spec.Containers[0].Resources.Requests
+	if _, exists := defaulted[v1.ResourceRtPeriod]; exists {
+		t.Error("Expected no rt_period default for a request without a runtime")
+	}
+	if _, exists := defaulted[v1.ResourceRtRuntime]; exists {
+		t.Error("Expected no rt_runtime default for a request without a runtime")
+	}
+}
+
 func TestDefaultRequestIsNotSetForReplicationController(t *testing.T) {
 	s := v1.PodSpec{}
 	s.Containers = []v1.Container{
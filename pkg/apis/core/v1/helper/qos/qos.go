@@ -80,7 +80,11 @@ func GetPodQOS(pod *v1.Pod) v1.PodQOSClass {
 			}
 		}
 
-		if !qosLimitsFound.HasAll(string(v1.ResourceMemory), string(v1.ResourceCPU)) {
+		// An RT runtime limit counts only when the RT period accompanies it:
+		// a runtime without a period is an incomplete RT spec and carries no
+		// guarantee.
+		if !qosLimitsFound.HasAll(string(v1.ResourceMemory), string(v1.ResourceCPU)) &&
+			!qosLimitsFound.HasAll(string(core.ResourceRtRuntime), string(core.ResourceRtPeriod)) {
 			isGuaranteed = false
 		}
 	}
@@ -126,6 +126,18 @@ func TestGetPodQOS(t *testing.T) {
 				}),
 			expected: v1.PodQOSBurstable,
 		},
+		{
+			pod: newPod("rt-runtime-without-period", []v1.Container{
+				newContainer("rt", addResource("rt_runtime", "100000", getResourceList("", "")), addResource("rt_runtime", "100000", getResourceList("", ""))),
+			}),
+			expected: v1.PodQOSBurstable,
+		},
+		{
+			pod: newPod("rt-runtime-with-period", []v1.Container{
+				newContainer("rt", addResource("rt_period", "1000000", addResource("rt_runtime", "100000", getResourceList("", ""))), addResource("rt_period", "1000000", addResource("rt_runtime", "100000", getResourceList("", "")))),
+			}),
+			expected: v1.PodQOSGuaranteed,
+		},
 	}
 	for id, testCase := range testCases {
 		if actual := GetPodQOS(testCase.pod); testCase.expected != actual {
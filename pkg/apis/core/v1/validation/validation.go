@@ -67,6 +67,59 @@ func ValidateResourceRequirements(requirements *v1.ResourceRequirements, fldPath
 	return allErrs
 }
 
+// ValidateRtPodSpec checks the real-time resource requests of every container
+// in the spec for common mistakes. It is meant to be reusable by admission
+// webhooks, so it only returns field-level errors and never mutates the spec.
+func ValidateRtPodSpec(spec *v1.PodSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i := range spec.InitContainers {
+		idxPath := fldPath.Child("initContainers").Index(i)
+		allErrs = append(allErrs, ValidateRtResourceRequirements(&spec.InitContainers[i].Resources, idxPath.Child("resources"))...)
+	}
+	for i := range spec.Containers {
+		idxPath := fldPath.Child("containers").Index(i)
+		allErrs = append(allErrs, ValidateRtResourceRequirements(&spec.Containers[i].Resources, idxPath.Child("resources"))...)
+	}
+	return allErrs
+}
+
+// ValidateRtResourceRequirements validates the real-time resources of a single
+// container: rt_runtime must not exceed rt_period, rt_cpu must be a positive
+// integer, and real-time containers cannot also carry cpu limits since the
+// cfs quota would conflict with the rt_runtime reservation.
+func ValidateRtResourceRequirements(requirements *v1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	period := requirements.Requests.CpuRtPeriod().Value()
+	runtime := requirements.Requests.CpuRtRuntime().Value()
+	cpus := requirements.Requests.CpuRt()
+
+	if period == 0 && runtime == 0 && cpus.IsZero() {
+		// not a real-time container
+		return allErrs
+	}
+
+	reqPath := fldPath.Child("requests")
+	if runtime == 0 {
+		// defaulting completes the rest of the request from the runtime, so
+		// rt_cpu or rt_period on their own are inconsistent
+		allErrs = append(allErrs, field.Required(reqPath.Key(string(v1.ResourceRtRuntime)), fmt.Sprintf("a real-time container must request %s", v1.ResourceRtRuntime)))
+	}
+	if runtime > period {
+		allErrs = append(allErrs, field.Invalid(reqPath.Key(string(v1.ResourceRtRuntime)), runtime, fmt.Sprintf("must be less than or equal to %s", v1.ResourceRtPeriod)))
+	}
+	if cpus.IsZero() {
+		allErrs = append(allErrs, field.Required(reqPath.Key(string(v1.ResourceRtCpu)), fmt.Sprintf("a real-time container must request %s", v1.ResourceRtCpu)))
+	} else if cpus.MilliValue()%int64(1000) != int64(0) {
+		allErrs = append(allErrs, field.Invalid(reqPath.Key(string(v1.ResourceRtCpu)), cpus.String(), isNotIntegerErrorMsg))
+	}
+	if cpuLimit, exists := requirements.Limits[v1.ResourceCPU]; exists && !cpuLimit.IsZero() {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("limits").Key(string(v1.ResourceCPU)), "cpu limits cannot be combined with real-time resources"))
+	}
+
+	return allErrs
+}
+
 func validateContainerResourceName(value string, fldPath *field.Path) field.ErrorList {
 	allErrs := validateResourceName(value, fldPath)
 	if len(strings.Split(value, "/")) == 1 {
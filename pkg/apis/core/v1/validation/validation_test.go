@@ -351,3 +351,138 @@ func TestAccumulateUniqueHostPorts(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRtResourceRequirements(t *testing.T) {
+	successCase := []struct {
+		Name         string
+		requirements v1.ResourceRequirements
+	}{
+		{
+			Name:         "No real-time resources",
+			requirements: v1.ResourceRequirements{},
+		},
+		{
+			Name: "Complete real-time request",
+			requirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+					v1.ResourceRtRuntime: resource.MustParse("100000"),
+					v1.ResourceRtCpu:     resource.MustParse("2"),
+				},
+			},
+		},
+		{
+			Name: "Real-time request with memory limit",
+			requirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+					v1.ResourceRtRuntime: resource.MustParse("100000"),
+					v1.ResourceRtCpu:     resource.MustParse("1"),
+				},
+				Limits: v1.ResourceList{
+					v1.ResourceMemory: resource.MustParse("1G"),
+				},
+			},
+		},
+	}
+	for _, tc := range successCase {
+		if errs := ValidateRtResourceRequirements(&tc.requirements, field.NewPath("resources")); len(errs) != 0 {
+			t.Errorf("%q unexpected error: %v", tc.Name, errs)
+		}
+	}
+
+	errorCase := []struct {
+		Name         string
+		requirements v1.ResourceRequirements
+	}{
+		{
+			Name: "Runtime greater than period",
+			requirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  resource.MustParse("100000"),
+					v1.ResourceRtRuntime: resource.MustParse("1000000"),
+					v1.ResourceRtCpu:     resource.MustParse("1"),
+				},
+			},
+		},
+		{
+			Name: "Missing cpu count",
+			requirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+					v1.ResourceRtRuntime: resource.MustParse("100000"),
+				},
+			},
+		},
+		{
+			Name: "Cpu count without runtime",
+			requirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtCpu: resource.MustParse("2"),
+				},
+			},
+		},
+		{
+			Name: "Fractional real-time cpus",
+			requirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+					v1.ResourceRtRuntime: resource.MustParse("100000"),
+					v1.ResourceRtCpu:     resource.MustParse("1500m"),
+				},
+			},
+		},
+		{
+			Name: "Real-time combined with cpu limits",
+			requirements: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+					v1.ResourceRtRuntime: resource.MustParse("100000"),
+					v1.ResourceRtCpu:     resource.MustParse("1"),
+				},
+				Limits: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		},
+	}
+	for _, tc := range errorCase {
+		if errs := ValidateRtResourceRequirements(&tc.requirements, field.NewPath("resources")); len(errs) == 0 {
+			t.Errorf("%q expected error", tc.Name)
+		}
+	}
+}
+
+func TestValidateRtPodSpec(t *testing.T) {
+	spec := v1.PodSpec{
+		Containers: []v1.Container{
+			{
+				Name: "ok",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+						v1.ResourceRtRuntime: resource.MustParse("100000"),
+						v1.ResourceRtCpu:     resource.MustParse("1"),
+					},
+				},
+			},
+			{
+				Name: "missing-cpus",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+						v1.ResourceRtRuntime: resource.MustParse("100000"),
+					},
+				},
+			},
+		},
+	}
+
+	errs := ValidateRtPodSpec(&spec, field.NewPath("spec"))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+	if want := "spec.containers[1].resources.requests[rt_cpu]"; errs[0].Field != want {
+		t.Errorf("error field = %q, want %q", errs[0].Field, want)
+	}
+}
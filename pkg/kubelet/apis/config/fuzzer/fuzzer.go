@@ -48,6 +48,8 @@ func Funcs(codecs runtimeserializer.CodecFactory) []interface{} {
 			obj.RuntimeRequestTimeout = metav1.Duration{Duration: 2 * time.Minute}
 			obj.CPUCFSQuota = true
 			obj.RTHCBS = false
+			obj.RTAggressiveReclaim = false
+			obj.RTSerializeStartup = false
 			obj.EventBurst = 10
 			obj.EventRecordQPS = 5
 			obj.EnableControllerAttachDetach = true
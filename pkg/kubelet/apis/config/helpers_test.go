@@ -149,9 +149,11 @@ var (
 		"Authorization.Webhook.CacheUnauthorizedTTL.Duration",
 		"CPUCFSQuota",
 		"CPUCFSQuotaPeriod.Duration",
+		"RTAggressiveReclaim",
 		"RTHCBS",
 		"RTHCBSPeriod",
 		"RTHCBSRuntime",
+		"RTSerializeStartup",
 		"CPUManagerPolicy",
 		"CPUManagerReconcilePeriod.Duration",
 		"TopologyManagerPolicy",
@@ -251,6 +251,14 @@ type KubeletConfiguration struct {
 	// CPURuntime sets the the CPU period of time in microseconds for the longest continuous period in which the tasks
 	// in a cgroup have access to CPU resources. Applicable to real-time scheduling tasks only. cpu.rt_runtime_us
 	RTRuntime metav1.Duration
+	// RTAggressiveReclaim makes the cpu manager reclaim the RT utilization of
+	// containers confirmed gone from the runtime even while other pods'
+	// statuses are still incomplete.
+	RTAggressiveReclaim bool
+	// RTSerializeStartup makes the cpu manager serialize the RT container
+	// starts racing at node startup, ordered by descending RT utilization,
+	// so placement after a reboot fragments the cpus as little as possible.
+	RTSerializeStartup bool
 	// maxOpenFiles is Number of files that can be opened by Kubelet process.
 	MaxOpenFiles int64
 	// contentType is contentType of requests sent to apiserver.
@@ -295,6 +295,12 @@ func autoConvert_v1beta1_KubeletConfiguration_To_config_KubeletConfiguration(in
 	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.RTPeriod, &out.RTPeriod, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.RTAggressiveReclaim, &out.RTAggressiveReclaim, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.RTSerializeStartup, &out.RTSerializeStartup, s); err != nil {
+		return err
+	}
 	out.MaxOpenFiles = in.MaxOpenFiles
 	out.ContentType = in.ContentType
 	if err := v1.Convert_Pointer_int32_To_int32(&in.KubeAPIQPS, &out.KubeAPIQPS, s); err != nil {
@@ -437,6 +443,12 @@ func autoConvert_config_KubeletConfiguration_To_v1beta1_KubeletConfiguration(in
 	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.RTRuntime, &out.RTRuntime, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.RTAggressiveReclaim, &out.RTAggressiveReclaim, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.RTSerializeStartup, &out.RTSerializeStartup, s); err != nil {
+		return err
+	}
 	out.MaxOpenFiles = in.MaxOpenFiles
 	out.ContentType = in.ContentType
 	if err := v1.Convert_int32_To_Pointer_int32(&in.KubeAPIQPS, &out.KubeAPIQPS, s); err != nil {
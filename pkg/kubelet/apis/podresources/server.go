@@ -33,18 +33,27 @@ type PodsProvider interface {
 	GetPods() []*v1.Pod
 }
 
+// RtResourcesProvider knows how to provide the RT cpus and utilization
+// assigned to the given container, reported in the same shape as the devices
+type RtResourcesProvider interface {
+	GetRtDevices(podUID, containerName string) []*v1alpha1.ContainerDevices
+}
+
 // podResourcesServer implements PodResourcesListerServer
 type podResourcesServer struct {
-	podsProvider    PodsProvider
-	devicesProvider DevicesProvider
+	podsProvider        PodsProvider
+	devicesProvider     DevicesProvider
+	rtResourcesProvider RtResourcesProvider
 }
 
 // NewPodResourcesServer returns a PodResourcesListerServer which lists pods provided by the PodsProvider
-// with device information provided by the DevicesProvider
-func NewPodResourcesServer(podsProvider PodsProvider, devicesProvider DevicesProvider) v1alpha1.PodResourcesListerServer {
+// with device information provided by the DevicesProvider and RT assignments
+// provided by the RtResourcesProvider
+func NewPodResourcesServer(podsProvider PodsProvider, devicesProvider DevicesProvider, rtResourcesProvider RtResourcesProvider) v1alpha1.PodResourcesListerServer {
 	return &podResourcesServer{
-		podsProvider:    podsProvider,
-		devicesProvider: devicesProvider,
+		podsProvider:        podsProvider,
+		devicesProvider:     devicesProvider,
+		rtResourcesProvider: rtResourcesProvider,
 	}
 }
 
@@ -61,9 +70,11 @@ func (p *podResourcesServer) List(ctx context.Context, req *v1alpha1.ListPodReso
 		}
 
 		for j, container := range pod.Spec.Containers {
+			devices := p.devicesProvider.GetDevices(string(pod.UID), container.Name)
+			devices = append(devices, p.rtResourcesProvider.GetRtDevices(string(pod.UID), container.Name)...)
 			pRes.Containers[j] = &v1alpha1.ContainerResources{
 				Name:    container.Name,
-				Devices: p.devicesProvider.GetDevices(string(pod.UID), container.Name),
+				Devices: devices,
 			}
 		}
 		podResources[i] = &pRes
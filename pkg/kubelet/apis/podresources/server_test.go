@@ -42,6 +42,11 @@ func (m *mockProvider) GetDevices(podUID, containerName string) []*v1alpha1.Cont
 	return args.Get(0).([]*v1alpha1.ContainerDevices)
 }
 
+func (m *mockProvider) GetRtDevices(podUID, containerName string) []*v1alpha1.ContainerDevices {
+	args := m.Called(podUID, containerName)
+	return args.Get(0).([]*v1alpha1.ContainerDevices)
+}
+
 func TestListPodResources(t *testing.T) {
 	podName := "pod-name"
 	podNamespace := "pod-namespace"
@@ -55,16 +60,29 @@ func TestListPodResources(t *testing.T) {
 		},
 	}
 
+	rtDevs := []*v1alpha1.ContainerDevices{
+		{
+			ResourceName: "rt_cpu",
+			DeviceIds:    []string{"0", "1"},
+		},
+		{
+			ResourceName: "rt_utilization",
+			DeviceIds:    []string{"0.5"},
+		},
+	}
+
 	for _, tc := range []struct {
 		desc             string
 		pods             []*v1.Pod
 		devices          []*v1alpha1.ContainerDevices
+		rtDevices        []*v1alpha1.ContainerDevices
 		expectedResponse *v1alpha1.ListPodResourcesResponse
 	}{
 		{
 			desc:             "no pods",
 			pods:             []*v1.Pod{},
 			devices:          []*v1alpha1.ContainerDevices{},
+			rtDevices:        []*v1alpha1.ContainerDevices{},
 			expectedResponse: &v1alpha1.ListPodResourcesResponse{},
 		},
 		{
@@ -85,7 +103,8 @@ func TestListPodResources(t *testing.T) {
 					},
 				},
 			},
-			devices: []*v1alpha1.ContainerDevices{},
+			devices:   []*v1alpha1.ContainerDevices{},
+			rtDevices: []*v1alpha1.ContainerDevices{},
 			expectedResponse: &v1alpha1.ListPodResourcesResponse{
 				PodResources: []*v1alpha1.PodResources{
 					{
@@ -119,7 +138,8 @@ func TestListPodResources(t *testing.T) {
 					},
 				},
 			},
-			devices: devs,
+			devices:   devs,
+			rtDevices: []*v1alpha1.ContainerDevices{},
 			expectedResponse: &v1alpha1.ListPodResourcesResponse{
 				PodResources: []*v1alpha1.PodResources{
 					{
@@ -135,12 +155,48 @@ func TestListPodResources(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "pod with RT cpus",
+			pods: []*v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      podName,
+						Namespace: podNamespace,
+						UID:       podUID,
+					},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name: containerName,
+							},
+						},
+					},
+				},
+			},
+			devices:   []*v1alpha1.ContainerDevices{},
+			rtDevices: rtDevs,
+			expectedResponse: &v1alpha1.ListPodResourcesResponse{
+				PodResources: []*v1alpha1.PodResources{
+					{
+						Name:      podName,
+						Namespace: podNamespace,
+						Containers: []*v1alpha1.ContainerResources{
+							{
+								Name:    containerName,
+								Devices: rtDevs,
+							},
+						},
+					},
+				},
+			},
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			m := new(mockProvider)
 			m.On("GetPods").Return(tc.pods)
 			m.On("GetDevices", string(podUID), containerName).Return(tc.devices)
-			server := NewPodResourcesServer(m, m)
+			m.On("GetRtDevices", string(podUID), containerName).Return(tc.rtDevices)
+			server := NewPodResourcesServer(m, m, m)
 			resp, err := server.List(context.TODO(), &v1alpha1.ListPodResourcesRequest{})
 			if err != nil {
 				t.Errorf("want err = %v, got %q", nil, err)
@@ -23,4 +23,10 @@ const (
 	// cmd line flag (--node-ip). This ip is verified with the cloudprovider as valid by
 	// the cloud-controller-manager
 	AnnotationProvidedIPAddr = "alpha.kubernetes.io/provided-node-ip"
+
+	// AnnotationRtPerSocketUtilization carries the RT utilization currently
+	// allocated on the node, aggregated by socket, as a JSON object keyed by
+	// socket id. It is set by the kubelet when the cpu manager runs the
+	// real-time policy.
+	AnnotationRtPerSocketUtilization = "alpha.kubernetes.io/rt-per-socket-utilization"
 )
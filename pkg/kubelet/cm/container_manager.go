@@ -107,12 +107,31 @@ type ContainerManager interface {
 	// GetDevices returns information about the devices assigned to pods and containers
 	GetDevices(podUID, containerName string) []*podresourcesapi.ContainerDevices
 
+	// GetRtDevices returns the RT cpus and utilization assigned to a
+	// container, in the same shape as GetDevices so the pod resources API can
+	// report them alongside the devices. It returns nil for non RT containers.
+	GetRtDevices(podUID, containerName string) []*podresourcesapi.ContainerDevices
+
+	// GetRtUtilizationHeadroom returns the RT utilization still assignable
+	// on the node and the total allocatable RT utilization. ok is false when
+	// the cpu manager policy does not manage RT resources.
+	GetRtUtilizationHeadroom() (available, capacity float64, ok bool)
+
+	// GetPerSocketRtUtilization returns the RT utilization allocated on the
+	// node aggregated by socket id, nil when the cpu manager policy does not
+	// manage RT resources.
+	GetPerSocketRtUtilization() map[int]float64
+
 	// ShouldResetExtendedResourceCapacity returns whether or not the extended resources should be zeroed,
 	// due to node recreation.
 	ShouldResetExtendedResourceCapacity() bool
 
 	// GetTopologyPodAdmitHandler returns an instance of the TopologyManager for Pod Admission
 	GetTopologyPodAdmitHandler() topologymanager.Manager
+
+	// GetRtPodAdmitHandler returns a PodAdmitHandler rejecting pods whose RT
+	// requirements cannot fit the node's remaining RT capacity.
+	GetRtPodAdmitHandler() lifecycle.PodAdmitHandler
 }
 
 type NodeConfig struct {
@@ -136,6 +155,8 @@ type NodeConfig struct {
 	EnforceRealTime                   bool
 	CpuRtPeriod                       time.Duration
 	CpuRtRuntime                      time.Duration
+	CpuRtAggressiveReclaim            bool
+	CpuRtSerializeStartup             bool
 	ExperimentalTopologyManagerPolicy string
 }
 
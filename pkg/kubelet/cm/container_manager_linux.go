@@ -23,12 +23,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 	"github.com/opencontainers/runc/libcontainer/cgroups/fs"
 	"github.com/opencontainers/runc/libcontainer/configs"
@@ -50,6 +53,7 @@ import (
 	podresourcesapi "k8s.io/kubernetes/pkg/kubelet/apis/podresources/v1alpha1"
 	"k8s.io/kubernetes/pkg/kubelet/cadvisor"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
+	cpumanagerstate "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
 	cputopology "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
 	"k8s.io/kubernetes/pkg/kubelet/cm/devicemanager"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
@@ -239,6 +243,9 @@ func NewContainerManager(mountUtil mount.Interface, cadvisorInterface cadvisor.I
 	if nodeConfig.EnforceRealTime {
 		capacity[v1.ResourceRtPeriod] = *resource.NewQuantity(nodeConfig.CpuRtPeriod.Microseconds(), resource.DecimalSI)
 		capacity[v1.ResourceRtRuntime] = *resource.NewQuantity(nodeConfig.CpuRtRuntime.Microseconds(), resource.DecimalSI)
+		// every core can host real-time tasks, the scheduler turns this and
+		// the runtime/period pair into the node's allocatable RT utilization
+		capacity[v1.ResourceRtCpu] = *resource.NewQuantity(int64(machineInfo.NumCores), resource.DecimalSI)
 	}
 	for k, v := range capacity {
 		internalCapacity[k] = v
@@ -330,7 +337,7 @@ func NewContainerManager(mountUtil mount.Interface, cadvisorInterface cadvisor.I
 			cm.GetNodeAllocatableReservation(),
 			nodeConfig.KubeletRootDir,
 			cm.topologyManager,
-			cpumanager.NodeConfig{RTRuntime: nodeConfig.CpuRtRuntime, RTPeriod: nodeConfig.CpuRtPeriod},
+			cpumanager.NodeConfig{RTRuntime: nodeConfig.CpuRtRuntime, RTPeriod: nodeConfig.CpuRtPeriod, RTAggressiveReclaim: nodeConfig.CpuRtAggressiveReclaim, RTSerializeStartup: nodeConfig.CpuRtSerializeStartup},
 		)
 		if err != nil {
 			klog.Errorf("failed to initialize cpu manager: %v", err)
@@ -591,6 +598,49 @@ func (cm *containerManagerImpl) Start(node *v1.Node,
 		cm.cpuManager.Start(cpumanager.ActivePodsFunc(activePods), sourcesReady, podStatusProvider, runtimeService)
 	}
 
+	// Periodically compare the cpu manager's RT bookkeeping against the
+	// utilization actually applied in the cgroups.
+	if cm.EnforceRealTime {
+		cm.periodicTasks = append(cm.periodicTasks, func() {
+			cpuMount, ok := cm.subsystems.MountPoints["cpu"]
+			if !ok {
+				return
+			}
+			tracked := float64(0)
+			if rtState, ok := cm.cpuManager.State().(*cpumanagerstate.RtState); ok {
+				for _, util := range rtState.CpuToUtilMap() {
+					tracked += util
+				}
+			}
+			if err := RecordRtUtilizationDrift(cpuMount, tracked); err != nil {
+				klog.Errorf("[ContainerManager] failed to record RT utilization drift: %v", err)
+			}
+		})
+		// A hot-unplugged cpu cannot honor the RT bandwidth reserved on it:
+		// let the cpu manager release the affected assignments as soon as
+		// the refreshed machine info reports the cpu gone.
+		cm.periodicTasks = append(cm.periodicTasks, func() {
+			machineInfo, err := cm.cadvisorInterface.MachineInfo()
+			if err != nil {
+				klog.Errorf("[ContainerManager] failed to refresh machine info: %v", err)
+				return
+			}
+			cm.cpuManager.HandleTopologyRefresh(machineInfo)
+		})
+		// SIGUSR2 dumps the current RT allocation table to the log, so a
+		// live node can be inspected without restarting the kubelet at a
+		// higher verbosity.
+		rtDumpSignal := make(chan os.Signal, 1)
+		signal.Notify(rtDumpSignal, unix.SIGUSR2)
+		go func() {
+			for range rtDumpSignal {
+				if table, ok := cm.cpuManager.DumpRtAllocations(); ok {
+					klog.Infof("[ContainerManager] %s", table)
+				}
+			}
+		}()
+	}
+
 	// cache the node Info including resource capacity and
 	// allocatable of the node
 	cm.nodeInfo = node
@@ -685,6 +735,10 @@ func (cm *containerManagerImpl) GetTopologyPodAdmitHandler() topologymanager.Man
 	return cm.topologyManager
 }
 
+func (cm *containerManagerImpl) GetRtPodAdmitHandler() lifecycle.PodAdmitHandler {
+	return NewRtAdmitHandler(cm.cpuManager)
+}
+
 func (cm *containerManagerImpl) SystemCgroupsLimit() v1.ResourceList {
 	cpuLimit := int64(0)
 
@@ -930,6 +984,41 @@ func (cm *containerManagerImpl) GetDevices(podUID, containerName string) []*podr
 	return cm.deviceManager.GetDevices(podUID, containerName)
 }
 
+// rtUtilizationResourceName is the pseudo resource under which the pod
+// resources API reports the per-cpu RT utilization of a container.
+const rtUtilizationResourceName = "rt_utilization"
+
+func (cm *containerManagerImpl) GetRtDevices(podUID, containerName string) []*podresourcesapi.ContainerDevices {
+	cpus, util, ok := cm.cpuManager.GetRtCPUSetAndUtil(podUID, containerName)
+	if !ok {
+		return nil
+	}
+
+	cpuIds := make([]string, 0, cpus.Size())
+	for _, cpu := range cpus.ToSlice() {
+		cpuIds = append(cpuIds, strconv.Itoa(cpu))
+	}
+
+	return []*podresourcesapi.ContainerDevices{
+		{
+			ResourceName: string(v1.ResourceRtCpu),
+			DeviceIds:    cpuIds,
+		},
+		{
+			ResourceName: rtUtilizationResourceName,
+			DeviceIds:    []string{strconv.FormatFloat(util, 'f', -1, 64)},
+		},
+	}
+}
+
+func (cm *containerManagerImpl) GetPerSocketRtUtilization() map[int]float64 {
+	return cm.cpuManager.GetPerSocketRtUtilization()
+}
+
+func (cm *containerManagerImpl) GetRtUtilizationHeadroom() (float64, float64, bool) {
+	return cm.cpuManager.GetRtUtilizationHeadroom()
+}
+
 func (cm *containerManagerImpl) ShouldResetExtendedResourceCapacity() bool {
 	return cm.deviceManager.ShouldResetExtendedResourceCapacity()
 }
@@ -113,6 +113,18 @@ func (cm *containerManagerStub) GetDevices(_, _ string) []*podresourcesapi.Conta
 	return nil
 }
 
+func (cm *containerManagerStub) GetRtDevices(_, _ string) []*podresourcesapi.ContainerDevices {
+	return nil
+}
+
+func (cm *containerManagerStub) GetPerSocketRtUtilization() map[int]float64 {
+	return nil
+}
+
+func (cm *containerManagerStub) GetRtUtilizationHeadroom() (float64, float64, bool) {
+	return 0, 0, false
+}
+
 func (cm *containerManagerStub) ShouldResetExtendedResourceCapacity() bool {
 	return cm.shouldResetExtendedResourceCapacity
 }
@@ -121,6 +133,10 @@ func (cm *containerManagerStub) GetTopologyPodAdmitHandler() topologymanager.Man
 	return nil
 }
 
+func (cm *containerManagerStub) GetRtPodAdmitHandler() lifecycle.PodAdmitHandler {
+	return NewRtAdmitHandler(nil)
+}
+
 func NewStubContainerManager() ContainerManager {
 	return &containerManagerStub{shouldResetExtendedResourceCapacity: false}
 }
@@ -174,6 +174,18 @@ func (cm *containerManagerImpl) GetDevices(_, _ string) []*podresourcesapi.Conta
 	return nil
 }
 
+func (cm *containerManagerImpl) GetRtDevices(_, _ string) []*podresourcesapi.ContainerDevices {
+	return nil
+}
+
+func (cm *containerManagerImpl) GetPerSocketRtUtilization() map[int]float64 {
+	return nil
+}
+
+func (cm *containerManagerImpl) GetRtUtilizationHeadroom() (float64, float64, bool) {
+	return 0, 0, false
+}
+
 func (cm *containerManagerImpl) ShouldResetExtendedResourceCapacity() bool {
 	return false
 }
@@ -181,3 +193,7 @@ func (cm *containerManagerImpl) ShouldResetExtendedResourceCapacity() bool {
 func (cm *containerManagerImpl) GetTopologyPodAdmitHandler() topologymanager.Manager {
 	return nil
 }
+
+func (cm *containerManagerImpl) GetRtPodAdmitHandler() lifecycle.PodAdmitHandler {
+	return NewRtAdmitHandler(nil)
+}
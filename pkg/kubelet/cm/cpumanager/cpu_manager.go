@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"math"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,6 +37,7 @@ import (
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
 	"k8s.io/kubernetes/pkg/kubelet/config"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/metrics"
 	"k8s.io/kubernetes/pkg/kubelet/status"
 )
 
@@ -48,6 +51,21 @@ type runtimeService interface {
 type NodeConfig struct {
 	RTPeriod  time.Duration
 	RTRuntime time.Duration
+	// RTNodeUtilCeiling caps the aggregate RT utilization across all cpus of
+	// the node, leaving global headroom on top of the per-cpu cap. Zero
+	// disables the aggregate check.
+	RTNodeUtilCeiling float64
+	// RTAggressiveReclaim makes removeStaleState reclaim the RT utilization
+	// of containers confirmed gone from the runtime even while other pods'
+	// statuses are still incomplete.
+	RTAggressiveReclaim bool
+	// RTSerializeStartup serializes concurrent RT container starts ordered
+	// by descending RT utilization, so the placement after a node reboot is
+	// deterministic and fragments the cpus as little as possible.
+	RTSerializeStartup bool
+	// RTFitStrategy selects how the real-time policy picks the cpus of a
+	// container. Empty selects the default worst fit.
+	RTFitStrategy RtFitStrategy
 }
 
 type policyName string
@@ -70,6 +88,11 @@ type Manager interface {
 	// that container and any CPUs dedicated to the container are freed.
 	RemoveContainer(containerID string) error
 
+	// UpdateContainer re-runs the placement for a container whose requests
+	// changed in place, relocating it if needed. When the new request does
+	// not fit the previous assignment is restored and an error is returned.
+	UpdateContainer(p *v1.Pod, c *v1.Container, containerID string) error
+
 	// State returns a read-only interface to the internal CPU manager state.
 	State() state.Reader
 
@@ -77,6 +100,45 @@ type Manager interface {
 	// and is consulted to achieve NUMA aware resource alignment among this
 	// and other resource controllers.
 	GetTopologyHints(v1.Pod, v1.Container) map[string][]topologymanager.TopologyHint
+
+	// GetRtContainers returns the ids of the containers currently holding RT
+	// utilization, so callers can reconcile them against the active pods. It
+	// returns nil when the active policy does not manage RT resources.
+	GetRtContainers() []string
+
+	// GetRtCPUSetAndUtil returns the cpus and the RT utilization assigned to
+	// the named container of a pod. The last return is false when the
+	// container holds no RT assignment.
+	GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool)
+
+	// GetPerSocketRtUtilization returns the RT utilization currently
+	// allocated on the node aggregated by socket id. It returns nil when the
+	// active policy does not manage RT resources.
+	GetPerSocketRtUtilization() map[int]float64
+
+	// GetRtUtilizationHeadroom returns the RT utilization still assignable
+	// on the node and the total allocatable RT utilization, both summed
+	// across the RT-eligible cpus. ok is false when the active policy does
+	// not manage RT resources.
+	GetRtUtilizationHeadroom() (available, capacity float64, ok bool)
+
+	// DumpRtAllocations returns a human readable table of the current RT
+	// allocations, container by container and cpu by cpu, for live debugging.
+	// ok is false when the active policy does not manage RT resources.
+	DumpRtAllocations() (string, bool)
+
+	// DrainRtState releases every RT allocation and zeroes the RT bandwidth
+	// of the affected containers, for node maintenance ahead of a policy
+	// change. It returns the ids of the drained containers so the caller can
+	// restart or evict them. It returns nil when the active policy does not
+	// manage RT resources.
+	DrainRtState() []string
+
+	// HandleTopologyRefresh re-checks the RT assignments against a refreshed
+	// machine topology, releasing those on cpus that went offline so the
+	// next reconcile pass re-places or fails the affected containers. It is
+	// a no-op when the active policy does not manage RT resources.
+	HandleTopologyRefresh(machineInfo *cadvisorapi.MachineInfo)
 }
 
 type manager struct {
@@ -109,6 +171,24 @@ type manager struct {
 	// sourcesReady provides the readiness of kubelet configuration sources such as apiserver update readiness.
 	// We use it to determine when we can purge inactive pods from checkpointed state.
 	sourcesReady config.SourcesReady
+
+	// rtPeriodUs is the node RT period in microseconds under a real-time
+	// policy, zero otherwise. The reconcile updates scale the per-container
+	// RT runtime to it when re-applying resources through the CRI.
+	rtPeriodUs int64
+
+	// rtContainerIDs maps a pod UID and container name to the id of the RT
+	// container last admitted under that name, so RT assignments can be
+	// looked up by the pod resources API, which does not know container ids.
+	rtContainerIDs map[string]string
+
+	// aggressiveRtReclaim reclaims the RT utilization of containers confirmed
+	// gone from the runtime even while other pods' statuses are incomplete.
+	aggressiveRtReclaim bool
+
+	// rtStartGate, when non nil, serializes the RT container starts racing
+	// through AddContainer, letting the highest utilizations place first.
+	rtStartGate *rtStartGate
 }
 
 var _ Manager = &manager{}
@@ -155,7 +235,7 @@ func NewManager(cpuPolicyName string, reconcilePeriod time.Duration, machineInfo
 		numReservedCPUs := int(math.Ceil(reservedCPUsFloat))
 		policy = NewStaticPolicy(topo, numReservedCPUs, specificCPUs, affinity)
 
-	case PolicyRealTime:
+	case PolicyRealTime, PolicyRealTimeExclusive:
 		var err error
 		topo, err = topology.Discover(machineInfo, numaNodeInfo)
 		if err != nil {
@@ -180,11 +260,45 @@ func NewManager(cpuPolicyName string, reconcilePeriod time.Duration, machineInfo
 			return nil, fmt.Errorf("[cpumanager] real-time policy need a runtime greater than zero")
 		}
 
+		// RT bandwidth reserved for the system and kube daemons is written to
+		// the reserved cgroups and must not be handed out to containers.
+		runtimeUs := runtime.Microseconds()
+		if reservedRt, ok := nodeAllocatableReservation[v1.ResourceRtRuntime]; ok {
+			runtimeUs -= reservedRt.Value()
+			if runtimeUs <= 0 {
+				return nil, fmt.Errorf("[cpumanager] reserved RT runtime %d leaves no RT runtime allocatable to containers", reservedRt.Value())
+			}
+		}
+
+		// The per-cpu allocatable utilization is a fraction of cpu time: a
+		// value outside (0, 1] means the node is misconfigured and would
+		// silently over-commit RT bandwidth.
+		allocableRtUtil := float64(runtimeUs) / float64(period.Microseconds())
+		if allocableRtUtil <= 0 || allocableRtUtil > 1 {
+			return nil, fmt.Errorf("[cpumanager] allocatable RT utilization %f outside (0, 1]: check the configured rt runtime and period", allocableRtUtil)
+		}
+
 		// Take the ceiling of the reservation, since fractional CPUs cannot be
 		// exclusively allocated.
 		reservedCPUsFloat := float64(reservedCPUs.MilliValue()) / 1000
 		numReservedCPUs := int(math.Ceil(reservedCPUsFloat))
-		policy = NewRealTimePolicy(topo, numReservedCPUs, specificCPUs, float64(runtime.Microseconds())/float64(period.Microseconds()))
+		if err := validateRtReservedCPUs(topo, specificCPUs); err != nil {
+			return nil, err
+		}
+		if policyName(cpuPolicyName) == PolicyRealTimeExclusive {
+			policy = NewExclusiveRealTimePolicy(topo, numReservedCPUs, specificCPUs, allocableRtUtil, nodeConfig.RTNodeUtilCeiling)
+		} else {
+			policy = NewRealTimePolicy(topo, numReservedCPUs, specificCPUs, allocableRtUtil, nodeConfig.RTNodeUtilCeiling)
+		}
+
+		switch nodeConfig.RTFitStrategy {
+		case "":
+			// keep the default set by the constructor
+		case RtFitWorstFit, RtFitNumaBalanced:
+			policy.(*realTimePolicy).fitStrategy = nodeConfig.RTFitStrategy
+		default:
+			return nil, fmt.Errorf("[cpumanager] unknown RT fit strategy: %q", nodeConfig.RTFitStrategy)
+		}
 
 	default:
 		return nil, fmt.Errorf("unknown policy: \"%s\"", cpuPolicyName)
@@ -195,8 +309,10 @@ func NewManager(cpuPolicyName string, reconcilePeriod time.Duration, machineInfo
 		return nil, fmt.Errorf("could not initialize checkpoint manager: %v", err)
 	}
 
-	if policyName(cpuPolicyName) == PolicyRealTime {
-		stateImpl = state.NewRtState(stateImpl)
+	if policyName(cpuPolicyName) == PolicyRealTime || policyName(cpuPolicyName) == PolicyRealTimeExclusive {
+		// the checkpoint may carry a narrowed default set: the RT bookkeeping
+		// has to cover every RT-eligible cpu of the machine regardless
+		stateImpl = state.NewRtStateWithAssignableCPUs(stateImpl, topo.CPUDetails.CPUs().Difference(specificCPUs))
 	}
 
 	manager := &manager{
@@ -205,6 +321,14 @@ func NewManager(cpuPolicyName string, reconcilePeriod time.Duration, machineInfo
 		state:                      stateImpl,
 		topology:                   topo,
 		nodeAllocatableReservation: nodeAllocatableReservation,
+		rtContainerIDs:             make(map[string]string),
+		aggressiveRtReclaim:        nodeConfig.RTAggressiveReclaim,
+	}
+	if nodeConfig.RTSerializeStartup {
+		manager.rtStartGate = newRtStartGate(rtStartGateSettle)
+	}
+	if policyName(cpuPolicyName) == PolicyRealTime || policyName(cpuPolicyName) == PolicyRealTimeExclusive {
+		manager.rtPeriodUs = nodeConfig.RTPeriod.Microseconds()
 	}
 	manager.sourcesReady = &sourcesReadyStub{}
 	return manager, nil
@@ -226,13 +350,30 @@ func (m *manager) Start(activePods ActivePodsFunc, sourcesReady config.SourcesRe
 }
 
 func (m *manager) AddContainer(p *v1.Pod, c *v1.Container, containerID string) error {
+	if err := m.policy.Validate(p); err != nil {
+		klog.Errorf("[cpumanager] AddContainer invalid pod %s: %v", p.Name, err)
+		return err
+	}
+	if m.rtStartGate != nil && !c.Resources.Requests.CpuRtRuntime().IsZero() {
+		m.rtStartGate.Enter(containerID, c.Resources.Requests.CpuRtUtilization())
+		defer m.rtStartGate.Leave(containerID)
+	}
+	// the lock spans the whole fit-and-commit of the container, so a
+	// concurrent admission from another pod's sync cannot interleave between
+	// the headroom check and the state update
 	m.Lock()
+	m.releaseSupersededRtID(p, c, containerID)
 	err := m.policy.AddContainer(m.state, p, c, containerID)
 	if err != nil {
 		klog.Errorf("[cpumanager] AddContainer error: %v", err)
 		m.Unlock()
 		return err
 	}
+	if rtState, ok := m.state.(RtState); ok {
+		if _, ok := rtState.GetRtCPUSet(containerID); ok {
+			m.rtContainerIDs[rtContainerKey(string(p.UID), c.Name)] = containerID
+		}
+	}
 	cpus := m.state.GetCPUSetOrDefault(containerID)
 	m.Unlock()
 
@@ -241,18 +382,82 @@ func (m *manager) AddContainer(p *v1.Pod, c *v1.Container, containerID string) e
 		if err != nil {
 			klog.Errorf("[cpumanager] AddContainer error: %v", err)
 			m.Lock()
-			err := m.policy.RemoveContainer(m.state, containerID)
-			if err != nil {
-				klog.Errorf("[cpumanager] AddContainer rollback state error: %v", err)
+			if rollbackErr := m.policy.RemoveContainer(m.state, containerID); rollbackErr != nil {
+				klog.Errorf("[cpumanager] AddContainer rollback state error: %v", rollbackErr)
 			}
 			m.Unlock()
+			return err
 		}
-		return err
+		m.logRtAdmissionSummary(p, c, containerID)
+		return nil
 	}
 	klog.V(5).Infof("[cpumanager] update container resources is skipped due to cpu set is empty")
 	return nil
 }
 
+// releaseSupersededRtID releases the RT reservation the state may still hold
+// for the previous run of the container, identified by pod UID and container
+// name. A restarted container shows up with a new id before removeStaleState
+// had a chance to clean the old one, and the conservative cleanup can keep
+// bailing out on incomplete pod statuses; without this release the same
+// container would count its utilization twice for a whole reconcile cycle.
+// Called with the lock held.
+func (m *manager) releaseSupersededRtID(p *v1.Pod, c *v1.Container, containerID string) {
+	if c.Resources.Requests.CpuRtRuntime().IsZero() {
+		return
+	}
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return
+	}
+	oldID, ok := m.rtContainerIDs[rtContainerKey(string(p.UID), c.Name)]
+	if !ok || oldID == containerID {
+		return
+	}
+	if _, tracked := rtState.GetRtCPUSet(oldID); !tracked {
+		return
+	}
+	klog.Infof("[cpumanager] AddContainer: releasing the RT reservation of the previous run of container %s (old id: %s, new id: %s)", c.Name, oldID, containerID)
+	if err := m.policy.RemoveContainer(m.state, oldID); err != nil {
+		klog.Errorf("[cpumanager] AddContainer: failed to release the superseded RT reservation %s: %v", oldID, err)
+	}
+}
+
+// logRtAdmissionSummary logs a one line admission audit for RT containers at
+// V(2): pod name, requested RT utilization, assigned cpus and the RT
+// utilization headroom left on the node.
+func (m *manager) logRtAdmissionSummary(p *v1.Pod, c *v1.Container, containerID string) {
+	if !klog.V(2) {
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return
+	}
+	cpus, util, ok := rtState.GetRtCPUSetAndUtilOfContainer(containerID)
+	if !ok {
+		// not an RT container
+		return
+	}
+
+	headroom := float64(0)
+	if rtPolicy, ok := m.policy.(*realTimePolicy); ok {
+		headroom = rtPolicy.headroom(rtState.CpuToUtilMap())
+	}
+
+	klog.V(2).Info(rtAdmissionSummary(p, c, cpus, util, headroom))
+}
+
+// rtAdmissionSummary builds the one line RT admission summary logged at V(2).
+func rtAdmissionSummary(p *v1.Pod, c *v1.Container, cpus cpuset.CPUSet, util, headroom float64) string {
+	return fmt.Sprintf("[cpumanager] admitted RT container (pod: %s, container: %s, requested util: %.3f, cpus: %s, node RT headroom: %.3f)",
+		p.Name, c.Name, util, cpus.String(), headroom)
+}
+
 func (m *manager) RemoveContainer(containerID string) error {
 	m.Lock()
 	defer m.Unlock()
@@ -262,13 +467,195 @@ func (m *manager) RemoveContainer(containerID string) error {
 		klog.Errorf("[cpumanager] RemoveContainer error: %v", err)
 		return err
 	}
+	for key, id := range m.rtContainerIDs {
+		if id == containerID {
+			delete(m.rtContainerIDs, key)
+		}
+	}
 	return nil
 }
 
+// UpdateContainer handles an in-place change of a container's RT requests:
+// the old assignment is released, the new request is fitted from scratch
+// (possibly on different cpus) and the cgroups are updated. When the new
+// request cannot be placed, the previous assignment is restored so the
+// container keeps running with its old reservation.
+func (m *manager) UpdateContainer(p *v1.Pod, c *v1.Container, containerID string) error {
+	m.Lock()
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		m.Unlock()
+		return nil
+	}
+	oldSet, oldUtil, tracked := rtState.GetRtCPUSetAndUtilOfContainer(containerID)
+	if tracked {
+		if err := m.policy.RemoveContainer(m.state, containerID); err != nil {
+			m.Unlock()
+			return err
+		}
+	}
+	m.Unlock()
+
+	if err := m.AddContainer(p, c, containerID); err != nil {
+		if tracked {
+			m.Lock()
+			rtState.SetRtCPUSetAndUtilOfContainer(containerID, oldSet, oldUtil)
+			m.Unlock()
+		}
+		klog.Errorf("[cpumanager] UpdateContainer: new request of container %s does not fit, keeping the previous assignment: %v", containerID, err)
+		return err
+	}
+	return nil
+}
+
+// rtContainerKey identifies a container the way the pod resources API does,
+// by pod UID and container name.
+func rtContainerKey(podUID, containerName string) string {
+	return podUID + "/" + containerName
+}
+
+func (m *manager) GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return cpuset.CPUSet{}, 0, false
+	}
+	containerID, ok := m.rtContainerIDs[rtContainerKey(podUID, containerName)]
+	if !ok {
+		return cpuset.CPUSet{}, 0, false
+	}
+	return rtState.GetRtCPUSetAndUtilOfContainer(containerID)
+}
+
+func (m *manager) GetPerSocketRtUtilization() map[int]float64 {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return nil
+	}
+	policy, ok := m.policy.(*realTimePolicy)
+	if !ok {
+		return nil
+	}
+	return policy.PerSocketUtilization(rtState.CpuToUtilMap())
+}
+
+func (m *manager) GetRtUtilizationHeadroom() (available, capacity float64, ok bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return 0, 0, false
+	}
+	policy, ok := m.policy.(*realTimePolicy)
+	if !ok {
+		return 0, 0, false
+	}
+	for _, util := range rtState.CpuToUtilMap() {
+		capacity += policy.allocableRtUtil
+		if headroom := policy.allocableRtUtil - util; headroom > 0 {
+			available += headroom
+		}
+	}
+	return available, capacity, true
+}
+
+func (m *manager) DumpRtAllocations() (string, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return "", false
+	}
+	return rtAllocationTable(rtState), true
+}
+
+// rtAllocationTable formats the container and the per-cpu RT allocations,
+// sorted so consecutive dumps are comparable line by line.
+func rtAllocationTable(rtState RtState) string {
+	var b strings.Builder
+	b.WriteString("RT allocation table\ncontainers:\n")
+	containerIDs := rtState.GetRtContainers()
+	sort.Strings(containerIDs)
+	for _, containerID := range containerIDs {
+		cpus, util, ok := rtState.GetRtCPUSetAndUtilOfContainer(containerID)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: cpus=%s util=%.3f\n", containerID, cpus.String(), util)
+	}
+	b.WriteString("cpus:")
+	cpuToUtil := rtState.CpuToUtilMap()
+	cpus := make([]int, 0, len(cpuToUtil))
+	for cpu := range cpuToUtil {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	for _, cpu := range cpus {
+		fmt.Fprintf(&b, "\n  %d: util=%.3f", cpu, cpuToUtil[cpu])
+	}
+	return b.String()
+}
+
+func (m *manager) DrainRtState() []string {
+	m.Lock()
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		m.Unlock()
+		return nil
+	}
+	drained := rtState.GetRtContainers()
+	for _, containerID := range drained {
+		if err := m.policy.RemoveContainer(m.state, containerID); err != nil {
+			klog.Errorf("[cpumanager] DrainRtState: failed to release container %s: %v", containerID, err)
+		}
+	}
+	for key, id := range m.rtContainerIDs {
+		for _, containerID := range drained {
+			if id == containerID {
+				delete(m.rtContainerIDs, key)
+			}
+		}
+	}
+	m.Unlock()
+
+	// zero the RT bandwidth outside the lock: the allocations are already
+	// released, the cgroup writes only stop the drained containers from
+	// consuming runtime the state no longer accounts
+	for _, containerID := range drained {
+		err := m.containerRuntime.UpdateContainerResources(
+			containerID,
+			&runtimeapi.LinuxContainerResources{
+				CpuRtRuntime: 0,
+			})
+		if err != nil {
+			klog.Errorf("[cpumanager] DrainRtState: failed to zero the RT bandwidth of container %s: %v", containerID, err)
+		}
+	}
+	return drained
+}
+
 func (m *manager) State() state.Reader {
 	return m.state
 }
 
+func (m *manager) GetRtContainers() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return nil
+	}
+	return rtState.GetRtContainers()
+}
+
 func (m *manager) GetTopologyHints(pod v1.Pod, container v1.Container) map[string][]topologymanager.TopologyHint {
 	// Garbage collect any stranded resources before providing TopologyHints
 	m.removeStaleState()
@@ -276,12 +663,183 @@ func (m *manager) GetTopologyHints(pod v1.Pod, container v1.Container) map[strin
 	return m.policy.GetTopologyHints(m.state, pod, container)
 }
 
+// cleanOrphanedRtUtil drops RT utilization entries left behind without a
+// cpuset, so that a partial state update cannot permanently inflate the
+// per-cpu utilization.
+func (m *manager) cleanOrphanedRtUtil() {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return
+	}
+	if orphaned := rtState.CleanOrphanedUtil(); len(orphaned) > 0 {
+		klog.Warningf("[cpumanager] reconcileState: removed orphaned RT utilization of containers %v", orphaned)
+	}
+}
+
+func (m *manager) HandleTopologyRefresh(machineInfo *cadvisorapi.MachineInfo) {
+	if machineInfo == nil {
+		return
+	}
+	online := cpuset.NewBuilder()
+	for _, node := range machineInfo.Topology {
+		for _, core := range node.Cores {
+			for _, thread := range core.Threads {
+				online.Add(thread)
+			}
+		}
+	}
+	m.releaseOfflineRtAssignments(online.Result())
+}
+
+// releaseOfflineRtAssignments drops the RT assignments that overlap cpus no
+// longer online: the hot-unplugged cpus cannot honor the reserved bandwidth,
+// so the affected containers are released and re-placed on the remaining cpus
+// by the next reconcile pass, or fail admission when they no longer fit.
+func (m *manager) releaseOfflineRtAssignments(online cpuset.CPUSet) {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return
+	}
+	for _, containerID := range rtState.GetRtContainers() {
+		cpus, ok := rtState.GetRtCPUSet(containerID)
+		if !ok || cpus.IsSubsetOf(online) {
+			continue
+		}
+		klog.Errorf("[cpumanager] cpus \"%v\" assigned to RT container %s went offline: releasing the assignment so the container is re-placed", cpus.Difference(online), containerID)
+		rtState.Delete(containerID)
+	}
+}
+
+// recordRtSaturation publishes the number of cpus left without RT headroom,
+// a concise saturation signal for the node.
+func (m *manager) recordRtSaturation() {
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return
+	}
+	policy, ok := m.policy.(*realTimePolicy)
+	if !ok {
+		return
+	}
+	saturated := 0
+	for _, util := range rtState.CpuToUtilMap() {
+		if util+1e-9 >= policy.allocableRtUtil {
+			saturated++
+		}
+	}
+	metrics.CPURtSaturatedCount.Set(float64(saturated))
+}
+
+// recordRtContainerUtilization publishes the RT utilization each container
+// reserves as a per-container series labeled by pod and container name. The
+// state keys the reservations by container id, so the names are joined
+// through the pod statuses; reservations whose id cannot be resolved to a
+// running container are left out until a later pass resolves them.
+func (m *manager) recordRtContainerUtilization() {
+	m.Lock()
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		m.Unlock()
+		return
+	}
+	utils := make(map[string]float64)
+	for _, containerID := range rtState.GetRtContainers() {
+		if _, util, ok := rtState.GetRtCPUSetAndUtilOfContainer(containerID); ok {
+			utils[containerID] = util
+		}
+	}
+	m.Unlock()
+
+	metrics.ContainerRtUtilization.Reset()
+	if len(utils) == 0 {
+		return
+	}
+	for _, pod := range m.activePods() {
+		status, ok := m.podStatusProvider.GetPodStatus(pod.UID)
+		if !ok {
+			continue
+		}
+		allContainers := pod.Spec.InitContainers
+		allContainers = append(allContainers, pod.Spec.Containers...)
+		for i := range allContainers {
+			containerID, err := findContainerIDByName(&status, allContainers[i].Name)
+			if err != nil {
+				continue
+			}
+			if util, ok := utils[containerID]; ok {
+				metrics.ContainerRtUtilization.WithLabelValues(pod.Name, allContainers[i].Name).Set(util)
+			}
+		}
+	}
+}
+
+// rtReconcileIdle reports whether the reconcile walk over the active pods can
+// be skipped. Under a real-time policy the container cpusets only change
+// through RT assignments, so when the state tracks no RT container and no
+// active pod reserves RT cpu time the per-container status lookups and
+// runtime updates would all be no-ops.
+func (m *manager) rtReconcileIdle() bool {
+	// The exclusive policy moves the shared pool of the non RT containers
+	// whenever the RT assignments change, so its reconcile writes cannot be
+	// skipped.
+	if policy, ok := m.policy.(*realTimePolicy); !ok || policy.exclusive {
+		return false
+	}
+	m.Lock()
+	rtState, ok := m.state.(RtState)
+	idle := ok && len(rtState.GetRtContainers()) == 0
+	m.Unlock()
+	if !idle {
+		return false
+	}
+	for _, pod := range m.activePods() {
+		allContainers := pod.Spec.InitContainers
+		allContainers = append(allContainers, pod.Spec.Containers...)
+		for i := range allContainers {
+			// an RT container still waiting for its assignment is a
+			// pending add the reconcile loop has to pick up
+			if !allContainers[i].Resources.Requests.CpuRtRuntime().IsZero() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 type reconciledContainer struct {
 	podName       string
 	containerName string
 	containerID   string
 }
 
+const (
+	// reconcileFailureRt labels reconcile failures of containers reserving
+	// RT cpu time: their cgroup and utilization updates carry guarantees.
+	reconcileFailureRt = "rt"
+	// reconcileFailureGeneric labels the remaining reconcile failures.
+	reconcileFailureGeneric = "generic"
+)
+
+// reconcileFailureKind labels a reconcile failure of the given container for
+// the CPUManagerReconcileFailures metric: a failed update of a container
+// reserving RT cpu time leaves its bandwidth guarantees unapplied, so those
+// failures are reported separately.
+func reconcileFailureKind(container *v1.Container) string {
+	if !container.Resources.Requests.CpuRtRuntime().IsZero() {
+		return reconcileFailureRt
+	}
+	return reconcileFailureGeneric
+}
+
 func (m *manager) removeStaleState() {
 	// Only once all sources are ready do we attempt to remove any stale state.
 	// This ensures that the call to `m.activePods()` below will succeed with
@@ -339,11 +897,127 @@ func (m *manager) removeStaleState() {
 	}
 }
 
+// runtimeContainerLister is implemented by container runtimes that can list
+// their containers, which lets the RT reclamation confirm a container is gone.
+type runtimeContainerLister interface {
+	ListContainers(filter *runtimeapi.ContainerFilter) ([]*runtimeapi.Container, error)
+}
+
+// removeStaleRtState reclaims RT utilization more aggressively than
+// removeStaleState: the conservative path bails out entirely as soon as one
+// pod status or container id is incomplete, which can leave the utilization
+// of a crashed RT container allocated for a long time. A container confirmed
+// gone from the runtime can be released regardless of the other pods' states.
+func (m *manager) removeStaleRtState() {
+	if !m.sourcesReady.AllReady() {
+		return
+	}
+
+	lister, ok := m.containerRuntime.(runtimeContainerLister)
+	if !ok {
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return
+	}
+
+	rtContainers := rtState.GetRtContainers()
+	if len(rtContainers) == 0 {
+		return
+	}
+
+	containers, err := lister.ListContainers(&runtimeapi.ContainerFilter{})
+	if err != nil {
+		klog.Errorf("[cpumanager] removeStaleRtState: failed to list runtime containers: %v", err)
+		return
+	}
+	known := make(map[string]struct{}, len(containers))
+	for _, container := range containers {
+		known[container.Id] = struct{}{}
+	}
+
+	for _, containerID := range rtContainers {
+		if _, ok := known[containerID]; ok {
+			continue
+		}
+		klog.Errorf("[cpumanager] removeStaleRtState: reclaiming RT utilization of container gone from the runtime: %s", containerID)
+		if err := m.policy.RemoveContainer(m.state, containerID); err != nil {
+			klog.Errorf("[cpumanager] removeStaleRtState: failed to remove container %s, error: %v", containerID, err)
+		}
+	}
+}
+
+// runtimeResourcesReporter is implemented by container runtimes that can
+// report the linux resources currently applied to a container.
+type runtimeResourcesReporter interface {
+	GetContainerResources(id string) (*runtimeapi.LinuxContainerResources, error)
+}
+
+// reconcileRtDrift compares the cpuset the manager expects for each RT
+// container against the one the runtime reports as applied, re-applying the
+// expected set on drift. This catches runtimes that silently changed the
+// resources of a container behind the manager's back.
+func (m *manager) reconcileRtDrift() {
+	reporter, ok := m.containerRuntime.(runtimeResourcesReporter)
+	if !ok {
+		return
+	}
+
+	m.Lock()
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		m.Unlock()
+		return
+	}
+	expected := make(map[string]cpuset.CPUSet)
+	for _, containerID := range rtState.GetRtContainers() {
+		if cpus, ok := rtState.GetRtCPUSet(containerID); ok {
+			expected[containerID] = cpus
+		}
+	}
+	m.Unlock()
+
+	for containerID, cpus := range expected {
+		resources, err := reporter.GetContainerResources(containerID)
+		if err != nil || resources == nil {
+			continue
+		}
+		applied, err := cpuset.Parse(resources.CpusetCpus)
+		if err != nil {
+			klog.Errorf("[cpumanager] reconcileRtDrift: cannot parse the cpuset %q reported for container %s: %v", resources.CpusetCpus, containerID, err)
+			continue
+		}
+		if applied.Equals(cpus) {
+			continue
+		}
+		klog.Errorf("[cpumanager] reconcileRtDrift: the runtime reports cpuset \"%v\" for container %s, expected \"%v\": re-applying", applied, containerID, cpus)
+		if err := m.updateContainerCPUSet(containerID, cpus); err != nil {
+			klog.Errorf("[cpumanager] reconcileRtDrift: failed to update container (container id: %s, cpuset: \"%v\", error: %v)", containerID, cpus, err)
+		}
+	}
+}
+
 func (m *manager) reconcileState() (success []reconciledContainer, failure []reconciledContainer) {
 	success = []reconciledContainer{}
 	failure = []reconciledContainer{}
 
 	m.removeStaleState()
+	if m.aggressiveRtReclaim {
+		m.removeStaleRtState()
+	}
+	m.cleanOrphanedRtUtil()
+	m.reconcileRtDrift()
+	m.recordRtSaturation()
+	m.recordRtContainerUtilization()
+	if m.rtReconcileIdle() {
+		klog.V(5).Infof("[cpumanager] reconcileState: no RT containers tracked or requested, skipping the active pods")
+		return success, failure
+	}
 	for _, pod := range m.activePods() {
 		allContainers := pod.Spec.InitContainers
 		allContainers = append(allContainers, pod.Spec.Containers...)
@@ -351,6 +1025,7 @@ func (m *manager) reconcileState() (success []reconciledContainer, failure []rec
 		for _, container := range allContainers {
 			if !ok {
 				klog.Warningf("[cpumanager] reconcileState: skipping pod; status not found (pod: %s)", pod.Name)
+				metrics.CPUManagerReconcileFailures.WithLabelValues(reconcileFailureGeneric).Inc()
 				failure = append(failure, reconciledContainer{pod.Name, container.Name, ""})
 				break
 			}
@@ -358,6 +1033,7 @@ func (m *manager) reconcileState() (success []reconciledContainer, failure []rec
 			containerID, err := findContainerIDByName(&status, container.Name)
 			if err != nil {
 				klog.Warningf("[cpumanager] reconcileState: skipping container; ID not found in status (pod: %s, container: %s, error: %v)", pod.Name, container.Name, err)
+				metrics.CPUManagerReconcileFailures.WithLabelValues(reconcileFailureGeneric).Inc()
 				failure = append(failure, reconciledContainer{pod.Name, container.Name, ""})
 				continue
 			}
@@ -372,6 +1048,7 @@ func (m *manager) reconcileState() (success []reconciledContainer, failure []rec
 					err := m.AddContainer(pod, &container, containerID)
 					if err != nil {
 						klog.Errorf("[cpumanager] reconcileState: failed to add container (pod: %s, container: %s, container id: %s, error: %v)", pod.Name, container.Name, containerID, err)
+						metrics.CPUManagerReconcileFailures.WithLabelValues(reconcileFailureKind(&container)).Inc()
 						failure = append(failure, reconciledContainer{pod.Name, container.Name, containerID})
 						continue
 					}
@@ -386,6 +1063,7 @@ func (m *manager) reconcileState() (success []reconciledContainer, failure []rec
 			if cset.IsEmpty() {
 				// NOTE: This should not happen outside of tests.
 				klog.Infof("[cpumanager] reconcileState: skipping container; assigned cpuset is empty (pod: %s, container: %s)", pod.Name, container.Name)
+				metrics.CPUManagerReconcileFailures.WithLabelValues(reconcileFailureKind(&container)).Inc()
 				failure = append(failure, reconciledContainer{pod.Name, container.Name, containerID})
 				continue
 			}
@@ -394,6 +1072,7 @@ func (m *manager) reconcileState() (success []reconciledContainer, failure []rec
 			err = m.updateContainerCPUSet(containerID, cset)
 			if err != nil {
 				klog.Errorf("[cpumanager] reconcileState: failed to update container (pod: %s, container: %s, container id: %s, cpuset: \"%v\", error: %v)", pod.Name, container.Name, containerID, cset, err)
+				metrics.CPUManagerReconcileFailures.WithLabelValues(reconcileFailureKind(&container)).Inc()
 				failure = append(failure, reconciledContainer{pod.Name, container.Name, containerID})
 				continue
 			}
@@ -424,9 +1103,42 @@ func (m *manager) updateContainerCPUSet(containerID string, cpus cpuset.CPUSet)
 	// helpers_linux.go similar to what exists for pods.
 	// It would be better to pass the full container resources here instead of
 	// this patch-like partial resources.
-	return m.containerRuntime.UpdateContainerResources(
-		containerID,
-		&runtimeapi.LinuxContainerResources{
-			CpusetCpus: cpus.String(),
-		})
+	resources := &runtimeapi.LinuxContainerResources{
+		CpusetCpus: cpus.String(),
+	}
+	if mems, ok := m.rtCpusetMems(containerID, cpus); ok {
+		resources.CpusetMems = mems
+	}
+	// an RT container carries its bandwidth through the CRI as well, so a
+	// reconcile restores the full RT configuration even when the direct
+	// cgroup writes were lost
+	if rtState, ok := m.state.(RtState); ok && m.rtPeriodUs > 0 {
+		if _, util, ok := rtState.GetRtCPUSetAndUtilOfContainer(containerID); ok {
+			resources.CpuRtPeriod = m.rtPeriodUs
+			resources.CpuRtRuntime = int64(util * float64(m.rtPeriodUs))
+		}
+	}
+	return m.containerRuntime.UpdateContainerResources(containerID, resources)
+}
+
+// rtCpusetMems returns the NUMA nodes backing the given cpus, formatted for
+// cpuset.mems, for containers holding an RT assignment. Keeping the memory of
+// an RT container local to the NUMA nodes of its cpus avoids the remote
+// access latencies that would defeat the cpu time guarantees. Non RT
+// containers keep the runtime's default memory placement.
+func (m *manager) rtCpusetMems(containerID string, cpus cpuset.CPUSet) (string, bool) {
+	policy, ok := m.policy.(*realTimePolicy)
+	if !ok || policy.topology == nil {
+		return "", false
+	}
+	m.Lock()
+	defer m.Unlock()
+	rtState, ok := m.state.(RtState)
+	if !ok {
+		return "", false
+	}
+	if _, ok := rtState.GetRtCPUSet(containerID); !ok {
+		return "", false
+	}
+	return policy.topology.CPUDetails.KeepOnly(cpus).NUMANodes().String(), true
 }
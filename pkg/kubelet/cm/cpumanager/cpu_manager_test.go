@@ -18,8 +18,11 @@ package cpumanager
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,10 +35,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	"k8s.io/component-base/metrics/testutil"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
+	"k8s.io/kubernetes/pkg/kubelet/metrics"
 )
 
 type mockState struct {
@@ -95,6 +100,10 @@ func (p *mockPolicy) Name() string {
 func (p *mockPolicy) Start(s state.State) {
 }
 
+func (p *mockPolicy) Validate(pod *v1.Pod) error {
+	return nil
+}
+
 func (p *mockPolicy) AddContainer(s state.State, pod *v1.Pod, container *v1.Container, containerID string) error {
 	return p.err
 }
@@ -753,3 +762,843 @@ func TestCPUManagerAddWithResvList(t *testing.T) {
 		}
 	}
 }
+
+type mockListingRuntimeService struct {
+	mockRuntimeService
+	containers []*runtimeapi.Container
+}
+
+func (rt mockListingRuntimeService) ListContainers(filter *runtimeapi.ContainerFilter) ([]*runtimeapi.Container, error) {
+	return rt.containers, nil
+}
+
+func TestRemoveStaleRtState(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("rt-alive", cpuset.NewCPUSet(1), 0.2)
+	s.SetRtCPUSetAndUtilOfContainer("rt-gone", cpuset.NewCPUSet(0), 0.2)
+
+	mgr := &manager{
+		policy: NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:  s,
+		containerRuntime: mockListingRuntimeService{
+			containers: []*runtimeapi.Container{{Id: "rt-alive"}},
+		},
+		// the incomplete status of this pod makes the conservative
+		// removeStaleState bail out without reclaiming anything
+		activePods: func() []*v1.Pod {
+			return []*v1.Pod{{ObjectMeta: metav1.ObjectMeta{UID: "pod-uid", Name: "pod"}}}
+		},
+		podStatusProvider:   mockPodStatusProvider{found: false},
+		sourcesReady:        &sourcesReadyStub{},
+		rtContainerIDs:      make(map[string]string),
+		aggressiveRtReclaim: true,
+	}
+
+	mgr.removeStaleState()
+	if got, want := s.GetRtContainers(), []string{"rt-alive", "rt-gone"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("removeStaleState() reclaimed despite the incomplete pod status, state: %v", got)
+	}
+
+	mgr.removeStaleRtState()
+	if got, want := s.GetRtContainers(), []string{"rt-alive"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removeStaleRtState() state = %v, want %v", got, want)
+	}
+}
+
+func TestCPUManagerRealTimeOverCommit(t *testing.T) {
+	machineInfo := &cadvisorapi.MachineInfo{
+		NumCores: 4,
+		Topology: []cadvisorapi.Node{
+			{
+				Cores: []cadvisorapi.Core{
+					{Id: 0, Threads: []int{0}},
+					{Id: 1, Threads: []int{1}},
+					{Id: 2, Threads: []int{2}},
+					{Id: 3, Threads: []int{3}},
+				},
+			},
+		},
+	}
+
+	sDir, err := ioutil.TempDir("/tmp/", "cpu_manager_test")
+	if err != nil {
+		t.Fatalf("cannot create state file: %s", err.Error())
+	}
+	defer os.RemoveAll(sDir)
+
+	// runtime/period of 1.5 can never be enforced per cpu
+	nodeConfig := NodeConfig{
+		RTPeriod:  1000000 * time.Microsecond,
+		RTRuntime: 1500000 * time.Microsecond,
+	}
+	_, err = NewManager("real-time", 5*time.Second, machineInfo, nil, cpuset.NewCPUSet(),
+		v1.ResourceList{v1.ResourceCPU: *resource.NewQuantity(1, resource.DecimalSI)}, sDir, topologymanager.NewFakeManager(), nodeConfig)
+	if err == nil {
+		t.Fatal("NewManager() = nil error, want allocatable RT utilization out of range error")
+	}
+	if !strings.Contains(err.Error(), "outside (0, 1]") {
+		t.Errorf("NewManager() error = %v, want allocatable RT utilization out of range error", err)
+	}
+}
+
+func TestCPUManagerUpdateContainer(t *testing.T) {
+	makeRtPod := func(runtime int64) (*v1.Pod, *v1.Container) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "rt-pod", UID: "rt-pod-uid"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "rt",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+								v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+								v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		}
+		return pod, &pod.Spec.Containers[0]
+	}
+
+	s := state.NewRtState(state.NewMemoryState())
+	mgr := &manager{
+		policy:            NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:             s,
+		containerRuntime:  mockRuntimeService{},
+		activePods:        func() []*v1.Pod { return nil },
+		podStatusProvider: mockPodStatusProvider{},
+		sourcesReady:      &sourcesReadyStub{},
+		rtContainerIDs:    make(map[string]string),
+	}
+	mgr.policy.Start(s)
+
+	pod, container := makeRtPod(200000)
+	if err := mgr.AddContainer(pod, container, "rt-id"); err != nil {
+		t.Fatalf("AddContainer() error = %v", err)
+	}
+
+	// an increase that fits keeps the container assigned, with the new
+	// utilization accounted
+	pod, container = makeRtPod(400000)
+	if err := mgr.UpdateContainer(pod, container, "rt-id"); err != nil {
+		t.Fatalf("UpdateContainer() increase error = %v", err)
+	}
+	set, util, ok := s.GetRtCPUSetAndUtilOfContainer("rt-id")
+	if !ok || util != 0.4 {
+		t.Fatalf("UpdateContainer() increase: util = %f, assigned = %t, want 0.4, true", util, ok)
+	}
+
+	// an increase beyond the per-cpu cap is rejected and the previous
+	// assignment is restored
+	pod, container = makeRtPod(960000)
+	if err := mgr.UpdateContainer(pod, container, "rt-id"); err == nil {
+		t.Fatal("UpdateContainer() over-cap increase = nil, want error")
+	}
+	restoredSet, util, ok := s.GetRtCPUSetAndUtilOfContainer("rt-id")
+	if !ok || util != 0.4 || !restoredSet.Equals(set) {
+		t.Fatalf("UpdateContainer() over-cap increase: util = %f, cpus = %s, want the previous 0.4 on %s", util, restoredSet, set)
+	}
+
+	// a decrease always fits
+	pod, container = makeRtPod(100000)
+	if err := mgr.UpdateContainer(pod, container, "rt-id"); err != nil {
+		t.Fatalf("UpdateContainer() decrease error = %v", err)
+	}
+	if _, util, _ := s.GetRtCPUSetAndUtilOfContainer("rt-id"); util != 0.1 {
+		t.Fatalf("UpdateContainer() decrease: util = %f, want 0.1", util)
+	}
+}
+
+func TestCPUManagerRtContainerRestart(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rt-pod", UID: "rt-pod-uid"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "rt",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+							v1.ResourceRtRuntime: *resource.NewQuantity(200000, resource.DecimalSI),
+							v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+	container := &pod.Spec.Containers[0]
+
+	s := state.NewRtState(state.NewMemoryState())
+	mgr := &manager{
+		policy:            NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:             s,
+		containerRuntime:  mockRuntimeService{},
+		activePods:        func() []*v1.Pod { return nil },
+		podStatusProvider: mockPodStatusProvider{},
+		sourcesReady:      &sourcesReadyStub{},
+		rtContainerIDs:    make(map[string]string),
+	}
+	mgr.policy.Start(s)
+
+	if err := mgr.AddContainer(pod, container, "rt-id-1"); err != nil {
+		t.Fatalf("AddContainer() error = %v", err)
+	}
+	oldSet, _, ok := s.GetRtCPUSetAndUtilOfContainer("rt-id-1")
+	if !ok {
+		t.Fatalf("container not assigned")
+	}
+
+	// the container restarts with a new id within one reconcile cycle:
+	// removeStaleState has not reclaimed the old id yet when the reconcile
+	// add path re-admits the container under the new one
+	if err := mgr.AddContainer(pod, container, "rt-id-2"); err != nil {
+		t.Fatalf("AddContainer() after restart error = %v", err)
+	}
+
+	if _, _, ok := s.GetRtCPUSetAndUtilOfContainer("rt-id-1"); ok {
+		t.Errorf("the superseded id still holds an RT reservation")
+	}
+	newSet, util, ok := s.GetRtCPUSetAndUtilOfContainer("rt-id-2")
+	if !ok || util != 0.2 {
+		t.Fatalf("restarted container: util = %f, assigned = %t, want 0.2, true", util, ok)
+	}
+	// the released cpus are preferred again, so the reservation is inherited
+	if !newSet.Equals(oldSet) {
+		t.Errorf("restarted container moved from %s to %s", oldSet, newSet)
+	}
+	// no cpu carries the utilization of both runs
+	for cpu, cpuUtil := range s.CpuToUtilMap() {
+		if cpuUtil > 0.2+1e-9 {
+			t.Errorf("cpu %d double counts the restarted container: %f", cpu, cpuUtil)
+		}
+	}
+}
+
+type mockReportingRuntimeService struct {
+	mockRuntimeService
+	resources map[string]*runtimeapi.LinuxContainerResources
+	updated   map[string]string
+}
+
+func (rt *mockReportingRuntimeService) GetContainerResources(id string) (*runtimeapi.LinuxContainerResources, error) {
+	return rt.resources[id], nil
+}
+
+func (rt *mockReportingRuntimeService) UpdateContainerResources(id string, resources *runtimeapi.LinuxContainerResources) error {
+	rt.updated[id] = resources.CpusetCpus
+	return nil
+}
+
+func TestReconcileRtDrift(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("rt-drift", cpuset.NewCPUSet(1), 0.2)
+	s.SetRtCPUSetAndUtilOfContainer("rt-ok", cpuset.NewCPUSet(2), 0.2)
+
+	runtime := &mockReportingRuntimeService{
+		resources: map[string]*runtimeapi.LinuxContainerResources{
+			"rt-drift": {CpusetCpus: "0"},
+			"rt-ok":    {CpusetCpus: "2"},
+		},
+		updated: make(map[string]string),
+	}
+	mgr := &manager{
+		policy:           NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:            s,
+		containerRuntime: runtime,
+		rtContainerIDs:   make(map[string]string),
+	}
+
+	mgr.reconcileRtDrift()
+
+	if got, want := runtime.updated, map[string]string{"rt-drift": "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reconcileRtDrift() re-applied %v, want %v", got, want)
+	}
+}
+
+func TestCPUManagerConcurrentRtAdmission(t *testing.T) {
+	makeRtPod := func(uid, name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(uid)},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "rt",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+								v1.ResourceRtRuntime: *resource.NewQuantity(400000, resource.DecimalSI),
+								v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	s := state.NewRtState(state.NewMemoryState())
+	mgr := &manager{
+		policy:           NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:            s,
+		containerRuntime: mockRuntimeService{},
+		rtContainerIDs:   make(map[string]string),
+	}
+	mgr.policy.Start(s)
+
+	// two concurrent syncs admit more containers than the node can hold:
+	// 0.4 utilization each against a 0.95 per-cpu cap fits twice per cpu,
+	// 16 reservations on the 8 cpus of the machine
+	const perSync = 10
+	var admitted int64
+	var wg sync.WaitGroup
+	for worker := 0; worker < 2; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < perSync; i++ {
+				pod := makeRtPod(fmt.Sprintf("uid-%d-%d", worker, i), fmt.Sprintf("pod-%d-%d", worker, i))
+				containerID := fmt.Sprintf("rt-id-%d-%d", worker, i)
+				if err := mgr.AddContainer(pod, &pod.Spec.Containers[0], containerID); err == nil {
+					atomic.AddInt64(&admitted, 1)
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if got := s.OverCommittedCPUs(0.95); got != nil {
+		t.Errorf("concurrent admissions over-committed cpus %v", got)
+	}
+	if admitted != 16 {
+		t.Errorf("admitted %d containers, want 16", admitted)
+	}
+	total := float64(0)
+	for _, util := range s.CpuToUtilMap() {
+		total += util
+	}
+	if math.Abs(total-0.4*float64(admitted)) > 1e-9 {
+		t.Errorf("tracked utilization %f does not match the %d admitted reservations", total, admitted)
+	}
+}
+
+type mockRecordingRuntimeService struct {
+	mockRuntimeService
+	resources map[string]*runtimeapi.LinuxContainerResources
+}
+
+func (rt *mockRecordingRuntimeService) UpdateContainerResources(id string, resources *runtimeapi.LinuxContainerResources) error {
+	rt.resources[id] = resources
+	return nil
+}
+
+func TestUpdateContainerCPUSetPushesRtBandwidth(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("rt-id", cpuset.NewCPUSet(1), 0.2)
+
+	runtime := &mockRecordingRuntimeService{resources: make(map[string]*runtimeapi.LinuxContainerResources)}
+	mgr := &manager{
+		policy:           NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:            s,
+		containerRuntime: runtime,
+		rtContainerIDs:   make(map[string]string),
+		rtPeriodUs:       1000000,
+	}
+
+	// the reconcile update of an RT container restores the bandwidth fields
+	if err := mgr.updateContainerCPUSet("rt-id", cpuset.NewCPUSet(1)); err != nil {
+		t.Fatalf("updateContainerCPUSet() error = %v", err)
+	}
+	applied := runtime.resources["rt-id"]
+	if applied == nil {
+		t.Fatalf("no resources applied")
+	}
+	if applied.CpusetCpus != "1" {
+		t.Errorf("CpusetCpus = %q, want %q", applied.CpusetCpus, "1")
+	}
+	if applied.CpuRtPeriod != 1000000 || applied.CpuRtRuntime != 200000 {
+		t.Errorf("RT bandwidth = %d/%d, want 200000/1000000", applied.CpuRtRuntime, applied.CpuRtPeriod)
+	}
+
+	// a container without an RT reservation keeps the RT fields at zero
+	if err := mgr.updateContainerCPUSet("plain-id", cpuset.NewCPUSet(0, 2)); err != nil {
+		t.Fatalf("updateContainerCPUSet() error = %v", err)
+	}
+	applied = runtime.resources["plain-id"]
+	if applied.CpuRtPeriod != 0 || applied.CpuRtRuntime != 0 {
+		t.Errorf("RT bandwidth = %d/%d for a non-RT container, want 0/0", applied.CpuRtRuntime, applied.CpuRtPeriod)
+	}
+}
+
+type mockDrainingRuntimeService struct {
+	mockRuntimeService
+	rtRuntimes map[string]int64
+}
+
+func (rt *mockDrainingRuntimeService) UpdateContainerResources(id string, resources *runtimeapi.LinuxContainerResources) error {
+	rt.rtRuntimes[id] = resources.CpuRtRuntime
+	return nil
+}
+
+func TestCPUManagerDrainRtState(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(1), 0.2)
+	s.SetRtCPUSetAndUtilOfContainer("rt-b", cpuset.NewCPUSet(2), 0.3)
+
+	runtime := &mockDrainingRuntimeService{rtRuntimes: make(map[string]int64)}
+	mgr := &manager{
+		policy:           NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:            s,
+		containerRuntime: runtime,
+		rtContainerIDs:   map[string]string{"rt-pod-uid/rt-a": "rt-a"},
+	}
+
+	drained := mgr.DrainRtState()
+	if want := []string{"rt-a", "rt-b"}; !reflect.DeepEqual(drained, want) {
+		t.Fatalf("DrainRtState() = %v, want %v", drained, want)
+	}
+	if got := s.GetRtContainers(); len(got) != 0 {
+		t.Errorf("DrainRtState() left containers in state: %v", got)
+	}
+	for cpu, util := range s.CpuToUtilMap() {
+		if util != 0 {
+			t.Errorf("DrainRtState() left utilization %f on cpu %d", util, cpu)
+		}
+	}
+	if got, want := runtime.rtRuntimes, map[string]int64{"rt-a": 0, "rt-b": 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DrainRtState() wrote RT runtimes %v, want %v", got, want)
+	}
+	if len(mgr.rtContainerIDs) != 0 {
+		t.Errorf("DrainRtState() left container id mappings: %v", mgr.rtContainerIDs)
+	}
+}
+
+func TestRecordRtSaturation(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("rt-full", cpuset.NewCPUSet(1), 0.95)
+	s.SetRtCPUSetAndUtilOfContainer("rt-light", cpuset.NewCPUSet(2), 0.2)
+
+	mgr := &manager{
+		policy:         NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:          s,
+		rtContainerIDs: make(map[string]string),
+	}
+	metrics.CPURtSaturatedCount.Create(nil)
+	mgr.recordRtSaturation()
+
+	expected := `
+		# HELP kubelet_cpu_rt_saturated_count [ALPHA] Number of cpus whose RT utilization has reached the allocatable per-cpu cap, leaving no RT headroom.
+		# TYPE kubelet_cpu_rt_saturated_count gauge
+		kubelet_cpu_rt_saturated_count 1
+		`
+	if err := testutil.CollectAndCompare(metrics.CPURtSaturatedCount, strings.NewReader(expected), "kubelet_"+metrics.CPURtSaturatedCountKey); err != nil {
+		t.Errorf("recordRtSaturation() unexpected metric: %v", err)
+	}
+}
+
+func TestHandleTopologyRefreshReleasesOfflineCpus(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("rt-offline", cpuset.NewCPUSet(2), 0.3)
+	s.SetRtCPUSetAndUtilOfContainer("rt-online", cpuset.NewCPUSet(1), 0.2)
+
+	mgr := &manager{
+		policy:         NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:          s,
+		rtContainerIDs: make(map[string]string),
+	}
+
+	// cpu 2 is gone from the refreshed machine info
+	mgr.HandleTopologyRefresh(&cadvisorapi.MachineInfo{
+		NumCores: 3,
+		Topology: []cadvisorapi.Node{
+			{
+				Cores: []cadvisorapi.Core{
+					{Id: 0, Threads: []int{0}},
+					{Id: 1, Threads: []int{1}},
+					{Id: 3, Threads: []int{3}},
+				},
+			},
+		},
+	})
+
+	if got, want := s.GetRtContainers(), []string{"rt-online"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleTopologyRefresh() state = %v, want %v", got, want)
+	}
+	if got := s.CpuToUtilMap(); got[2] != 0 || got[1] != 0.2 {
+		t.Errorf("HandleTopologyRefresh() cpuToUtil = %v, want cpu 2 released and cpu 1 untouched", got)
+	}
+}
+
+type countingRuntimeService struct {
+	mockRuntimeService
+	updates int
+}
+
+func (rt *countingRuntimeService) UpdateContainerResources(id string, resources *runtimeapi.LinuxContainerResources) error {
+	rt.updates++
+	return nil
+}
+
+func makeNonRtReconcilePods(count int) ([]*v1.Pod, v1.PodStatus) {
+	pods := make([]*v1.Pod, count)
+	for i := 0; i < count; i++ {
+		pods[i] = &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("fakePod-%d", i),
+				UID:  types.UID(fmt.Sprintf("fakeUID-%d", i)),
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "fakeName",
+					},
+				},
+			},
+		}
+	}
+	status := v1.PodStatus{
+		Phase: v1.PodRunning,
+		ContainerStatuses: []v1.ContainerStatus{
+			{
+				Name:        "fakeName",
+				ContainerID: "docker://fakeID",
+			},
+		},
+	}
+	return pods, status
+}
+
+func TestReconcileStateRtIdleSkip(t *testing.T) {
+	makeManager := func(policy Policy, pods []*v1.Pod, status v1.PodStatus, runtime runtimeService) (*manager, RtState) {
+		s := state.NewRtState(state.NewMemoryState())
+		s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+		mgr := &manager{
+			policy:            policy,
+			state:             s,
+			containerRuntime:  runtime,
+			rtContainerIDs:    make(map[string]string),
+			activePods:        func() []*v1.Pod { return pods },
+			podStatusProvider: mockPodStatusProvider{podStatus: status, found: true},
+		}
+		mgr.sourcesReady = &sourcesReadyStub{}
+		return mgr, s
+	}
+
+	pods, status := makeNonRtReconcilePods(3)
+
+	// no RT assignments and no RT requests: the walk is skipped entirely
+	runtime := &countingRuntimeService{}
+	mgr, _ := makeManager(NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0), pods, status, runtime)
+	mgr.reconcileState()
+	if runtime.updates != 0 {
+		t.Errorf("reconcileState() issued %d updates on an idle RT node, want 0", runtime.updates)
+	}
+
+	// a tracked RT container keeps the walk alive
+	runtime = &countingRuntimeService{}
+	mgr, s := makeManager(NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0), pods, status, runtime)
+	s.SetRtCPUSetAndUtilOfContainer("fakeID", cpuset.NewCPUSet(1), 0.2)
+	mgr.reconcileState()
+	if runtime.updates == 0 {
+		t.Errorf("reconcileState() skipped the walk with a tracked RT container")
+	}
+
+	// a pending RT request keeps the walk alive even with an empty state
+	rtPods, _ := makeNonRtReconcilePods(1)
+	rtPods[0].Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+			v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+			v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+		},
+	}
+	mgr, _ = makeManager(NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0), rtPods, status, &countingRuntimeService{})
+	if mgr.rtReconcileIdle() {
+		t.Errorf("rtReconcileIdle() = true with a pending RT add")
+	}
+
+	// the exclusive policy always walks: the shared pool of the non RT
+	// containers follows the RT assignments
+	mgr, _ = makeManager(NewExclusiveRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0), pods, status, &countingRuntimeService{})
+	if mgr.rtReconcileIdle() {
+		t.Errorf("rtReconcileIdle() = true under the exclusive policy")
+	}
+}
+
+type recordingRuntimeService struct {
+	mockRuntimeService
+	resources map[string]*runtimeapi.LinuxContainerResources
+}
+
+func (rt *recordingRuntimeService) UpdateContainerResources(id string, resources *runtimeapi.LinuxContainerResources) error {
+	rt.resources[id] = resources
+	return nil
+}
+
+func TestUpdateContainerCPUSetRtMems(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3, 4, 5, 6, 7))
+	s.SetRtCPUSetAndUtilOfContainer("rt-socket1", cpuset.NewCPUSet(5, 6), 0.2)
+
+	runtime := &recordingRuntimeService{resources: make(map[string]*runtimeapi.LinuxContainerResources)}
+	mgr := &manager{
+		policy:           NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:            s,
+		containerRuntime: runtime,
+		rtContainerIDs:   make(map[string]string),
+	}
+
+	// the memory of an RT container follows the NUMA node of its cpus
+	if err := mgr.updateContainerCPUSet("rt-socket1", cpuset.NewCPUSet(5, 6)); err != nil {
+		t.Fatalf("updateContainerCPUSet() error = %v", err)
+	}
+	if mems := runtime.resources["rt-socket1"].CpusetMems; mems != "1" {
+		t.Errorf("cpuset.mems = %q for RT cpus on socket 1, want \"1\"", mems)
+	}
+
+	// non RT containers keep the runtime's default memory placement
+	if err := mgr.updateContainerCPUSet("non-rt", cpuset.NewCPUSet(0, 1)); err != nil {
+		t.Fatalf("updateContainerCPUSet() error = %v", err)
+	}
+	if mems := runtime.resources["non-rt"].CpusetMems; mems != "" {
+		t.Errorf("cpuset.mems = %q for a non RT container, want it unset", mems)
+	}
+}
+
+type orderRecordingRuntimeService struct {
+	mockRuntimeService
+	sync.Mutex
+	order []string
+}
+
+func (rt *orderRecordingRuntimeService) UpdateContainerResources(id string, resources *runtimeapi.LinuxContainerResources) error {
+	rt.Lock()
+	defer rt.Unlock()
+	rt.order = append(rt.order, id)
+	return nil
+}
+
+func TestAddContainerRtSerializedStartup(t *testing.T) {
+	makeRtPod := func(name string, runtime int64) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				UID:  types.UID(name + "-uid"),
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: name,
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+								v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+								v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3, 4, 5, 6, 7))
+	runtime := &orderRecordingRuntimeService{}
+	mgr := &manager{
+		policy:           NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:            s,
+		containerRuntime: runtime,
+		rtContainerIDs:   make(map[string]string),
+		rtStartGate:      newRtStartGate(rtStartGateSettle),
+	}
+
+	// three RT pods sync concurrently, as after a node reboot: the gate
+	// admits them by descending utilization regardless of the arrival order
+	pods := map[string]*v1.Pod{
+		"small-id":  makeRtPod("small", 200000),
+		"large-id":  makeRtPod("large", 600000),
+		"medium-id": makeRtPod("medium", 400000),
+	}
+	var wg sync.WaitGroup
+	for containerID, pod := range pods {
+		wg.Add(1)
+		go func(containerID string, pod *v1.Pod) {
+			defer wg.Done()
+			if err := mgr.AddContainer(pod, &pod.Spec.Containers[0], containerID); err != nil {
+				t.Errorf("AddContainer(%s) error = %v", containerID, err)
+			}
+		}(containerID, pod)
+	}
+	wg.Wait()
+
+	want := []string{"large-id", "medium-id", "small-id"}
+	if !reflect.DeepEqual(runtime.order, want) {
+		t.Errorf("AddContainer() placed the containers in order %v, want %v", runtime.order, want)
+	}
+}
+
+func Test_rtStartGate(t *testing.T) {
+	gate := newRtStartGate(50 * time.Millisecond)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for id, util := range map[string]float64{"a": 0.2, "b": 0.6, "c": 0.4} {
+		wg.Add(1)
+		go func(id string, util float64) {
+			defer wg.Done()
+			gate.Enter(id, util)
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+			gate.Leave(id)
+		}(id, util)
+	}
+	wg.Wait()
+
+	if want := []string{"b", "c", "a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("the gate admitted the starts in order %v, want %v", order, want)
+	}
+}
+
+func Test_rtAllocationTable(t *testing.T) {
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.NewCPUSet(0, 1),
+	})
+	s.SetRtCPUSetAndUtilOfContainer("a-id", cpuset.NewCPUSet(0, 1), 0.1)
+	s.SetRtCPUSetAndUtilOfContainer("b-id", cpuset.NewCPUSet(1), 0.25)
+
+	want := "RT allocation table\n" +
+		"containers:\n" +
+		"  a-id: cpus=0-1 util=0.100\n" +
+		"  b-id: cpus=1 util=0.250\n" +
+		"cpus:\n" +
+		"  0: util=0.100\n" +
+		"  1: util=0.350"
+	if got := rtAllocationTable(s); got != want {
+		t.Errorf("rtAllocationTable() = %q, want %q", got, want)
+	}
+}
+
+func TestReconcileStateFailureMetric(t *testing.T) {
+	pods, status := makeNonRtReconcilePods(2)
+	// the first pod reserves RT cpu time, the second does not: a failing
+	// runtime update must surface under a different label for each
+	pods[0].Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+			v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+			v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+		},
+	}
+
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("fakeID", cpuset.NewCPUSet(1), 0.1)
+
+	mgr := &manager{
+		policy:            NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:             s,
+		containerRuntime:  mockRuntimeService{err: fmt.Errorf("fake error")},
+		rtContainerIDs:    make(map[string]string),
+		activePods:        func() []*v1.Pod { return pods },
+		podStatusProvider: mockPodStatusProvider{podStatus: status, found: true},
+	}
+	mgr.sourcesReady = &sourcesReadyStub{}
+
+	metrics.CPUManagerReconcileFailures.Create(nil)
+	metrics.CPUManagerReconcileFailures.Reset()
+	mgr.reconcileState()
+
+	expected := `
+		# HELP kubelet_cpu_manager_reconcile_failures_total [ALPHA] Cumulative number of containers the cpu manager failed to reconcile, partitioned by the kind of failure.
+		# TYPE kubelet_cpu_manager_reconcile_failures_total counter
+		kubelet_cpu_manager_reconcile_failures_total{kind="generic"} 1
+		kubelet_cpu_manager_reconcile_failures_total{kind="rt"} 1
+		`
+	if err := testutil.CollectAndCompare(metrics.CPUManagerReconcileFailures, strings.NewReader(expected), "kubelet_"+metrics.CPUManagerReconcileFailuresKey); err != nil {
+		t.Errorf("reconcileState() unexpected failure metric: %v", err)
+	}
+}
+
+func TestRecordRtContainerUtilization(t *testing.T) {
+	pods, status := makeNonRtReconcilePods(1)
+	pods[0].Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+			v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+			v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+		},
+	}
+
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("fakeID", cpuset.NewCPUSet(1), 0.1)
+
+	mgr := &manager{
+		policy:            NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		state:             s,
+		containerRuntime:  mockRuntimeService{},
+		rtContainerIDs:    make(map[string]string),
+		activePods:        func() []*v1.Pod { return pods },
+		podStatusProvider: mockPodStatusProvider{podStatus: status, found: true},
+	}
+	mgr.sourcesReady = &sourcesReadyStub{}
+
+	metrics.ContainerRtUtilization.Create(nil)
+	metrics.ContainerRtUtilization.Reset()
+	mgr.recordRtContainerUtilization()
+
+	expected := `
+		# HELP kubelet_container_rt_utilization [ALPHA] RT utilization reserved by each container, labeled by pod and container name.
+		# TYPE kubelet_container_rt_utilization gauge
+		kubelet_container_rt_utilization{container="fakeName",pod="fakePod-0"} 0.1
+		`
+	if err := testutil.CollectAndCompare(metrics.ContainerRtUtilization, strings.NewReader(expected), "kubelet_"+metrics.ContainerRtUtilizationKey); err != nil {
+		t.Errorf("recordRtContainerUtilization() unexpected metric: %v", err)
+	}
+
+	// a released reservation drops its series on the next pass
+	s.Delete("fakeID")
+	mgr.recordRtContainerUtilization()
+	if err := testutil.CollectAndCompare(metrics.ContainerRtUtilization, strings.NewReader(""), "kubelet_"+metrics.ContainerRtUtilizationKey); err != nil {
+		t.Errorf("recordRtContainerUtilization() kept a stale series: %v", err)
+	}
+}
+
+func BenchmarkReconcileStateAllNonRt(b *testing.B) {
+	pods, status := makeNonRtReconcilePods(100)
+	for name, policy := range map[string]Policy{
+		"real-time":           NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+		"real-time-exclusive": NewExclusiveRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0),
+	} {
+		b.Run(name, func(b *testing.B) {
+			s := state.NewRtState(state.NewMemoryState())
+			s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+			mgr := &manager{
+				policy:            policy,
+				state:             s,
+				containerRuntime:  &countingRuntimeService{},
+				rtContainerIDs:    make(map[string]string),
+				activePods:        func() []*v1.Pod { return pods },
+				podStatusProvider: mockPodStatusProvider{podStatus: status, found: true},
+			}
+			mgr.sourcesReady = &sourcesReadyStub{}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				mgr.reconcileState()
+			}
+		})
+	}
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package cpumanager
 
 import (
+	cadvisorapi "github.com/google/cadvisor/info/v1"
 	"k8s.io/api/core/v1"
 	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
 	"k8s.io/kubernetes/pkg/kubelet/config"
 	"k8s.io/kubernetes/pkg/kubelet/status"
@@ -48,6 +50,11 @@ func (m *fakeManager) RemoveContainer(containerID string) error {
 	return nil
 }
 
+func (m *fakeManager) UpdateContainer(pod *v1.Pod, container *v1.Container, containerID string) error {
+	klog.Infof("[fake cpumanager] UpdateContainer (pod: %s, container: %s, container id: %s)", pod.Name, container.Name, containerID)
+	return nil
+}
+
 func (m *fakeManager) GetTopologyHints(pod v1.Pod, container v1.Container) map[string][]topologymanager.TopologyHint {
 	klog.Infof("[fake cpumanager] Get Topology Hints")
 	return map[string][]topologymanager.TopologyHint{}
@@ -57,6 +64,40 @@ func (m *fakeManager) State() state.Reader {
 	return m.state
 }
 
+func (m *fakeManager) GetRtContainers() []string {
+	klog.Info("[fake cpumanager] GetRtContainers()")
+	return nil
+}
+
+func (m *fakeManager) GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool) {
+	klog.Infof("[fake cpumanager] GetRtCPUSetAndUtil (pod uid: %s, container: %s)", podUID, containerName)
+	return cpuset.CPUSet{}, 0, false
+}
+
+func (m *fakeManager) GetPerSocketRtUtilization() map[int]float64 {
+	klog.Info("[fake cpumanager] GetPerSocketRtUtilization()")
+	return nil
+}
+
+func (m *fakeManager) GetRtUtilizationHeadroom() (float64, float64, bool) {
+	klog.Info("[fake cpumanager] GetRtUtilizationHeadroom()")
+	return 0, 0, false
+}
+
+func (m *fakeManager) DumpRtAllocations() (string, bool) {
+	klog.Info("[fake cpumanager] DumpRtAllocations()")
+	return "", false
+}
+
+func (m *fakeManager) DrainRtState() []string {
+	klog.Info("[fake cpumanager] DrainRtState()")
+	return nil
+}
+
+func (m *fakeManager) HandleTopologyRefresh(machineInfo *cadvisorapi.MachineInfo) {
+	klog.Info("[fake cpumanager] HandleTopologyRefresh()")
+}
+
 // NewFakeManager creates empty/fake cpu manager
 func NewFakeManager() Manager {
 	return &fakeManager{
@@ -26,6 +26,10 @@ import (
 type Policy interface {
 	Name() string
 	Start(s state.State)
+	// Validate checks the pod spec for requests the policy can never
+	// satisfy, so the pod can be rejected before AddContainer causes any
+	// side effect.
+	Validate(pod *v1.Pod) error
 	// AddContainer call is idempotent
 	AddContainer(s state.State, pod *v1.Pod, container *v1.Container, containerID string) error
 	// RemoveContainer call is idempotent
@@ -43,6 +43,13 @@ func (p *nonePolicy) Start(s state.State) {
 	klog.Info("[cpumanager] none policy: Start")
 }
 
+func (p *nonePolicy) Validate(pod *v1.Pod) error {
+	if podRequestsRtResources(pod) {
+		klog.Warningf("[cpumanager] none policy: pod %s requests real-time resources, but the active policy does not enforce them; the pod will run without RT guarantees", pod.Name)
+	}
+	return nil
+}
+
 func (p *nonePolicy) AddContainer(s state.State, pod *v1.Pod, container *v1.Container, containerID string) error {
 	return nil
 }
@@ -10,16 +10,43 @@ import (
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
+	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager/bitmask"
+	"k8s.io/kubernetes/pkg/kubelet/leaky"
 )
 
 // PolicyStatic is the name of the static policy
 const PolicyRealTime policyName = "real-time"
 
+// PolicyRealTimeExclusive is the name of the exclusive real-time policy
+const PolicyRealTimeExclusive policyName = "real-time-exclusive"
+
+// RtFitStrategy selects how the real-time policy picks the cpus of a
+// container among the ones with enough headroom.
+type RtFitStrategy string
+
+const (
+	// RtFitWorstFit places the container on the cpus with the most headroom
+	// left, regardless of their placement. It is the default.
+	RtFitWorstFit RtFitStrategy = "worst-fit"
+	// RtFitNumaBalanced first selects the NUMA node with the most aggregate
+	// RT headroom, then applies worst fit within it, balancing the RT load
+	// across sockets before the cpus of any one of them fill up.
+	RtFitNumaBalanced RtFitStrategy = "numa-balanced"
+)
+
 type RtState interface {
 	state.State
 	GetRtCPUSetAndUtilOfContainer(containerID string) (cpuset.CPUSet, float64, bool)
+	GetRtCPUSet(containerID string) (cpuset.CPUSet, bool)
 	SetRtCPUSetAndUtilOfContainer(containerID string, set cpuset.CPUSet, util float64)
 	CpuToUtilMap() map[int]float64
+	MaxFittingUtilization(allocableRtUtil float64) float64
+	CanAccommodate(set cpuset.CPUSet, util float64, allocableRtUtil float64) bool
+	OverCommittedCPUs(cap float64) []int
+	LoadAssignments(cpusets map[string]cpuset.CPUSet, utils map[string]float64) error
+	VerifyRelease(containerID string) error
+	GetRtContainers() []string
+	CleanOrphanedUtil() []string
 }
 
 //
@@ -27,17 +54,39 @@ type realTimePolicy struct {
 	topology *topology.CPUTopology
 	// allocable utilization
 	allocableRtUtil float64
+	// node-wide RT utilization ceiling, summed across all cpus. Zero means
+	// no aggregate ceiling is enforced.
+	nodeRtUtilCeiling float64
 	// number of reserved cpus
 	numReservedCpus int
 	// unassignable cpus
 	reservedCpus cpuset.CPUSet
+	// deterministicFit makes the fit functions scan candidate cpus in
+	// ascending id order instead of map iteration order, so placement is
+	// reproducible. It is meant for tests and benchmarks.
+	deterministicFit bool
+	// preferredCpus remembers the cpus last assigned to a pod's container,
+	// keyed by pod UID and container name, so a restarted container goes
+	// back to the same cpus when they still fit.
+	preferredCpus map[string]cpuset.CPUSet
+	// flatTopology disables the NUMA-aware features when topology.Discover
+	// returned incomplete per-cpu detail; allocation then only considers
+	// utilization, not placement.
+	flatTopology bool
+	// exclusive refuses to place a container on a cpu already holding any RT
+	// utilization, pinning at most one RT container per cpu regardless of
+	// the remaining headroom.
+	exclusive bool
+	// fitStrategy selects how fitCpus picks the cpus of a container among
+	// the admittable ones.
+	fitStrategy RtFitStrategy
 }
 
 // Ensure realTimePolicy implements Policy interface
 var _ Policy = &realTimePolicy{}
 
 //
-func NewRealTimePolicy(topology *topology.CPUTopology, numReservedCPUs int, reservedCPUs cpuset.CPUSet, allocableRtUtil float64) Policy {
+func NewRealTimePolicy(topology *topology.CPUTopology, numReservedCPUs int, reservedCPUs cpuset.CPUSet, allocableRtUtil float64, nodeRtUtilCeiling float64) Policy {
 	allCPUs := topology.CPUDetails.CPUs()
 	var reserved cpuset.CPUSet
 	if reservedCPUs.Size() > 0 {
@@ -55,15 +104,65 @@ func NewRealTimePolicy(topology *topology.CPUTopology, numReservedCPUs int, rese
 		panic(fmt.Sprintf("[cpumanager] unable to reserve the required amount of CPUs (size of %s did not equal %d)", reserved, numReservedCPUs))
 	}
 
+	flatTopology := !topologyDetailComplete(topology)
+	if flatTopology {
+		klog.Warningf("[cpumanager] real-time policy: topology detail is incomplete, falling back to flat allocation")
+	}
+
 	return &realTimePolicy{
-		topology:        topology,
-		numReservedCpus: numReservedCPUs,
-		reservedCpus:    reservedCPUs,
-		allocableRtUtil: allocableRtUtil,
+		topology:          topology,
+		numReservedCpus:   numReservedCPUs,
+		reservedCpus:      reservedCPUs,
+		allocableRtUtil:   allocableRtUtil,
+		nodeRtUtilCeiling: nodeRtUtilCeiling,
+		preferredCpus:     make(map[string]cpuset.CPUSet),
+		flatTopology:      flatTopology,
+		fitStrategy:       RtFitWorstFit,
+	}
+}
+
+// validateRtReservedCPUs checks an explicitly reserved cpu list against the
+// machine topology: every reserved cpu must exist on the machine and at
+// least one cpu must remain assignable to RT containers.
+func validateRtReservedCPUs(topo *topology.CPUTopology, reservedCPUs cpuset.CPUSet) error {
+	if reservedCPUs.Size() == 0 {
+		return nil
+	}
+	allCPUs := topo.CPUDetails.CPUs()
+	if !reservedCPUs.IsSubsetOf(allCPUs) {
+		return fmt.Errorf("[cpumanager] reserved cpus \"%v\" are not cpus of this machine (\"%v\")", reservedCPUs.Difference(allCPUs), allCPUs)
+	}
+	if reservedCPUs.Equals(allCPUs) {
+		return fmt.Errorf("[cpumanager] reserving every cpu of the machine (\"%v\") leaves none assignable to RT containers", allCPUs)
+	}
+	return nil
+}
+
+// topologyDetailComplete reports whether every assignable cpu has socket,
+// core and NUMA node detail. topology.Discover may leave cpus out of
+// CPUDetails when cadvisor could not place them.
+func topologyDetailComplete(topo *topology.CPUTopology) bool {
+	for cpu := 0; cpu < topo.NumCPUs; cpu++ {
+		if _, ok := topo.CPUDetails[cpu]; !ok {
+			return false
+		}
 	}
+	return true
+}
+
+// NewExclusiveRealTimePolicy returns a real-time policy that pins at most
+// one RT container per cpu: AddContainer refuses cpus already holding any RT
+// utilization, regardless of their remaining headroom.
+func NewExclusiveRealTimePolicy(topology *topology.CPUTopology, numReservedCPUs int, reservedCPUs cpuset.CPUSet, allocableRtUtil float64, nodeRtUtilCeiling float64) Policy {
+	p := NewRealTimePolicy(topology, numReservedCPUs, reservedCPUs, allocableRtUtil, nodeRtUtilCeiling).(*realTimePolicy)
+	p.exclusive = true
+	return p
 }
 
 func (p realTimePolicy) Name() string {
+	if p.exclusive {
+		return string(PolicyRealTimeExclusive)
+	}
 	return string(PolicyRealTime)
 }
 
@@ -78,12 +177,78 @@ func (p *realTimePolicy) initState(s state.State) error {
 	assignments := s.GetCPUAssignments()
 
 	allCPUs := p.topology.CPUDetails.CPUs()
-	s.SetDefaultCPUSet(allCPUs)
 
+	rtState, ok := s.(RtState)
+	if !ok {
+		s.SetDefaultCPUSet(allCPUs)
+		for containerID := range assignments {
+			s.Delete(containerID)
+		}
+		return nil
+	}
+
+	// Preserve the assignments the state still fully tracks, with both a
+	// cpuset on cpus of this machine and a utilization, so RT containers
+	// keep their cpus across a policy restart. The rest is the residue of an
+	// old topology or of an interrupted update: it is dropped here and
+	// reconcile re-places those containers from scratch.
+	cpusets := make(map[string]cpuset.CPUSet)
+	utils := make(map[string]float64)
+	for containerID, cset := range assignments {
+		_, util, tracked := rtState.GetRtCPUSetAndUtilOfContainer(containerID)
+		if !tracked || !cset.IsSubsetOf(allCPUs) {
+			continue
+		}
+		cpusets[containerID] = cset
+		utils[containerID] = util
+	}
+
+	s.SetDefaultCPUSet(allCPUs)
 	for containerID := range assignments {
-		s.Delete(containerID)
+		if _, preserved := cpusets[containerID]; !preserved {
+			s.Delete(containerID)
+		}
 	}
+	return rtState.LoadAssignments(cpusets, utils)
+}
 
+// Validate rejects pods whose RT requests can never be satisfied, before
+// AddContainer causes any side effect.
+func (p *realTimePolicy) Validate(pod *v1.Pod) error {
+	allContainers := []v1.Container{}
+	allContainers = append(allContainers, pod.Spec.InitContainers...)
+	allContainers = append(allContainers, pod.Spec.Containers...)
+	for idx := range allContainers {
+		container := &allContainers[idx]
+		reqPeriod, reqRuntime, reqCpus := rtRequests(container)
+		if reqPeriod == 0 && reqRuntime == 0 && reqCpus == 0 {
+			// not a real-time container
+			continue
+		}
+		if reqPeriod < 0 || reqRuntime < 0 {
+			return fmt.Errorf("container %s: rt period and runtime must be non negative, got period %d, runtime %d", container.Name, reqPeriod, reqRuntime)
+		}
+		if reqRuntime > reqPeriod {
+			return fmt.Errorf("container %s: rt runtime %d exceeds rt period %d", container.Name, reqRuntime, reqPeriod)
+		}
+		if reqRuntime > 0 && reqCpus <= 0 {
+			return fmt.Errorf("container %s: rt runtime requested without rt cpus", container.Name)
+		}
+		if reqPeriod > 0 {
+			if reqUtil := float64(reqRuntime) / float64(reqPeriod); reqUtil >= p.allocableRtUtil {
+				return fmt.Errorf("container %s: requested utilization %f can never fit under the per-cpu cap %f", container.Name, reqUtil, p.allocableRtUtil)
+			}
+		}
+	}
+
+	// Ephemeral containers come and go outside the pod's resource contract
+	// and must not reserve RT bandwidth.
+	for idx := range pod.Spec.EphemeralContainers {
+		container := v1.Container(pod.Spec.EphemeralContainers[idx].EphemeralContainerCommon)
+		if reqPeriod, reqRuntime, reqCpus := rtRequests(&container); reqPeriod != 0 || reqRuntime != 0 || reqCpus != 0 {
+			return fmt.Errorf("ephemeral container %s: ephemeral containers cannot reserve real-time resources", container.Name)
+		}
+	}
 	return nil
 }
 
@@ -91,6 +256,15 @@ func (p *realTimePolicy) AddContainer(s state.State, pod *v1.Pod, container *v1.
 
 	rtState := s.(RtState)
 
+	// The pod infra (pause) container runs in the pod level cgroup where the
+	// RT bandwidth is applied, so it can consume a tiny slice of it. That
+	// slice is negligible and the container never declares RT requests, so it
+	// is deliberately kept out of the utilization bookkeeping; skip it even
+	// when a container carrying the infra name shows up with requests.
+	if container.Name == leaky.PodInfraContainerName {
+		return nil
+	}
+
 	reqPeriod, reqRuntime, reqCpus := rtRequests(container)
 	reqUtil := float64(0)
 	if reqPeriod != 0 {
@@ -102,21 +276,125 @@ func (p *realTimePolicy) AddContainer(s state.State, pod *v1.Pod, container *v1.
 		return nil
 	}
 
-	if _, _, ok := rtState.GetRtCPUSetAndUtilOfContainer(containerID); ok {
+	if _, ok := rtState.GetRtCPUSet(containerID); ok {
 		klog.Infof("[cpumanager] real-time policy: container already assigned to cpus, skipping (container: %s, container id: %s)", container.Name, containerID)
 		return nil
 	}
 
-	cpus := p.worstFit(rtState.CpuToUtilMap(), reqUtil, reqCpus)
-	if int64(len(cpus)) < reqCpus {
-		err := fmt.Errorf("container %s doesn't fit", containerID)
-		klog.Errorf("[cpumanager] unable to allocate %d CPUs (container id: %s, error: %v)", reqCpus, containerID, err)
+	cpuToUtil := rtState.CpuToUtilMap()
+
+	if p.exclusive {
+		// in exclusive mode a cpu already holding any RT utilization is off
+		// limits: mark it full so no fit function considers it
+		for cpu, util := range cpuToUtil {
+			if util > 0 {
+				cpuToUtil[cpu] = 1
+			}
+		}
+	}
+
+	// enforce the node-wide aggregate ceiling before looking at per-cpu fits
+	if p.nodeRtUtilCeiling > 0 {
+		totalUtil := float64(0)
+		for _, util := range cpuToUtil {
+			totalUtil += util
+		}
+		if totalUtil+reqUtil*float64(reqCpus) > p.nodeRtUtilCeiling {
+			err := fmt.Errorf("container %s exceeds the node RT utilization ceiling %f (current: %f, requested: %f)",
+				containerID, p.nodeRtUtilCeiling, totalUtil, reqUtil*float64(reqCpus))
+			klog.Errorf("[cpumanager] real-time policy: %v", err)
+			return err
+		}
+	}
+
+	// a restarted container goes back to the cpus of its previous run when
+	// they still have room for the requested utilization
+	preferenceKey := rtPreferenceKey(pod, container)
+	fittingCpusSet, reused := p.reusePreferredCpus(cpuToUtil, preferenceKey, reqUtil, reqCpus)
+	if !reused {
+		cpus, err := p.fitCpus(cpuToUtil, reqUtil, reqCpus, p.preferredCpus[preferenceKey])
+		if err != nil {
+			klog.Errorf("[cpumanager] real-time policy: invalid request (container id: %s, error: %v)", containerID, err)
+			return err
+		}
+		if int64(len(cpus)) < reqCpus {
+			err := fmt.Errorf("container %s doesn't fit", containerID)
+			klog.Errorf("[cpumanager] unable to allocate %d CPUs (container id: %s, error: %v)", reqCpus, containerID, err)
+			return err
+		}
+		fittingCpusSet = cpuset.NewCPUSet(cpus...)
+	} else {
+		klog.Infof("[cpumanager] real-time policy: reusing previous cpus %s (container: %s, container id: %s)", fittingCpusSet, container.Name, containerID)
+	}
+
+	// last line of defense before the cpuset reaches the cgroups: a fit
+	// function bug must not commit an over-committing assignment
+	if !rtState.CanAccommodate(fittingCpusSet, reqUtil, p.allocableRtUtil) {
+		err := fmt.Errorf("cpus \"%v\" cannot accommodate %f additional RT utilization for container %s", fittingCpusSet, reqUtil, containerID)
+		klog.Errorf("[cpumanager] real-time policy: %v", err)
 		return err
 	}
-	fittingCpusSet := cpuset.NewCPUSet(cpus...)
 
 	rtState.SetRtCPUSetAndUtilOfContainer(containerID, fittingCpusSet, reqUtil)
+	p.preferredCpus[preferenceKey] = fittingCpusSet
+
+	return nil
+}
+
+// rtPreferenceKey identifies a container across restarts: the container id
+// changes on every restart, the pod UID and container name do not.
+func rtPreferenceKey(pod *v1.Pod, container *v1.Container) string {
+	return string(pod.UID) + "/" + container.Name
+}
+
+// reusePreferredCpus returns the cpus last assigned to the container when
+// they are still enough and each of them can take the requested utilization.
+func (p *realTimePolicy) reusePreferredCpus(cpuToUtil map[int]float64, preferenceKey string, reqUtil float64, reqCpus int64) (cpuset.CPUSet, bool) {
+	preferred, ok := p.preferredCpus[preferenceKey]
+	if !ok || int64(preferred.Size()) != reqCpus {
+		return cpuset.CPUSet{}, false
+	}
+
+	for _, cpu := range preferred.ToSliceNoSort() {
+		util, ok := cpuToUtil[cpu]
+		if !ok || util+reqUtil >= p.allocableRtUtil {
+			return cpuset.CPUSet{}, false
+		}
+	}
+
+	return preferred, true
+}
 
+// AddPod reserves the RT containers of a pod atomically: every container
+// goes through AddContainer and the reservations already made are rolled
+// back when one of them does not fit, so a multi-container pod never leaves
+// a partial reservation behind. containerIDs maps the container names to the
+// ids the reservations are keyed by.
+func (p *realTimePolicy) AddPod(s state.State, pod *v1.Pod, containerIDs map[string]string) error {
+	added := []string{}
+	rollback := func() {
+		for _, containerID := range added {
+			if err := p.RemoveContainer(s, containerID); err != nil {
+				klog.Errorf("[cpumanager] real-time policy: rolling back reservation of container %s: %v", containerID, err)
+			}
+		}
+	}
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Resources.Requests.CpuRtRuntime().IsZero() {
+			continue
+		}
+		containerID, ok := containerIDs[container.Name]
+		if !ok {
+			rollback()
+			return fmt.Errorf("no container id for RT container %s of pod %s", container.Name, pod.Name)
+		}
+		if err := p.AddContainer(s, pod, container, containerID); err != nil {
+			rollback()
+			return err
+		}
+		added = append(added, containerID)
+	}
 	return nil
 }
 
@@ -124,7 +402,7 @@ func (p *realTimePolicy) RemoveContainer(s state.State, containerID string) erro
 	klog.Infof("[cpumanager] real-time policy: RemoveContainer (container id: %s)", containerID)
 	rtState := s.(RtState)
 
-	_, _, ok := rtState.GetRtCPUSetAndUtilOfContainer(containerID)
+	_, ok := rtState.GetRtCPUSet(containerID)
 	if !ok {
 		// container not assigned by real-time policy
 		return nil
@@ -132,42 +410,162 @@ func (p *realTimePolicy) RemoveContainer(s state.State, containerID string) erro
 
 	s.Delete(containerID)
 
+	if klog.V(4) {
+		if err := rtState.VerifyRelease(containerID); err != nil {
+			klog.Errorf("[cpumanager] real-time policy: residue after removing container %s: %v", containerID, err)
+		}
+	}
+
 	return nil
 }
 
 func (p realTimePolicy) GetTopologyHints(s state.State, pod v1.Pod, container v1.Container) map[string][]topologymanager.TopologyHint {
-	panic("implement me")
-}
+	reqPeriod, reqRuntime, reqCpus := rtRequests(&container)
+	if reqPeriod == 0 || reqRuntime == 0 || reqCpus <= 0 {
+		// no topology hints for non RT containers
+		return nil
+	}
+	reqUtil := float64(reqRuntime) / float64(reqPeriod)
 
-// firstFit assign the requests to the first admittable cpus it find
-func (p *realTimePolicy) firstFit(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64) []int {
-	var fittingCpus []int
-	for cpu, util := range cpuToUtil {
+	if p.flatTopology {
+		// without complete topology detail the NUMA affinities would be
+		// meaningless, let the TopologyManager treat the container as
+		// placement-agnostic
+		return nil
+	}
+
+	rtState := s.(RtState)
+
+	// consider only the cpus that can still take the requested utilization
+	var admittableCpus []int
+	for cpu, util := range rtState.CpuToUtilMap() {
 		if util+reqUtil < p.allocableRtUtil {
-			fittingCpus = append(fittingCpus, cpu)
-			if int64(len(fittingCpus)) == reqCpus {
-				break
-			}
+			admittableCpus = append(admittableCpus, cpu)
 		}
 	}
 
-	if int64(len(fittingCpus)) < reqCpus {
-		return nil
+	hints := p.generateRtTopologyHints(cpuset.NewCPUSet(admittableCpus...), rtState.CpuToUtilMap(), int(reqCpus))
+	klog.Infof("[cpumanager] real-time policy: TopologyHints generated for pod '%v', container '%v': %v", pod.Name, container.Name, hints)
+
+	return map[string][]topologymanager.TopologyHint{
+		string(v1.ResourceRtCpu): hints,
+	}
+}
+
+// generateRtTopologyHints generates a set of TopologyHints given the set of
+// cpus that can still take the requested RT utilization and the number of
+// cpus being requested.
+//
+// The affinities are expressed over the full NUMA node bitmask of the
+// topology, like the other hint providers, so that the TopologyManager can
+// merge them.
+func (p *realTimePolicy) generateRtTopologyHints(admittableCPUs cpuset.CPUSet, cpuToUtil map[int]float64, request int) []topologymanager.TopologyHint {
+	// Initialize minAffinitySize to include all NUMA Nodes.
+	minAffinitySize := p.topology.CPUDetails.NUMANodes().Size()
+
+	hints := []topologymanager.TopologyHint{}
+	bitmask.IterateBitMasks(p.topology.CPUDetails.NUMANodes().ToSlice(), func(mask bitmask.BitMask) {
+		// First, update minAffinitySize for the current request size.
+		cpusInMask := p.topology.CPUDetails.CPUsInNUMANodes(mask.GetBits()...).Size()
+		if cpusInMask >= request && mask.Count() < minAffinitySize {
+			minAffinitySize = mask.Count()
+		}
+
+		// Then check to see if we have enough admittable cpus in the current
+		// NUMA node bitmask to satisfy the request.
+		numMatching := 0
+		for _, c := range admittableCPUs.ToSlice() {
+			if mask.IsSet(p.topology.CPUDetails[c].NUMANodeID) {
+				numMatching++
+			}
+		}
+
+		// If we don't, then move onto the next combination.
+		if numMatching < request {
+			return
+		}
+
+		// Otherwise, create a new hint from the NUMA node bitmask and add it
+		// to the list of hints. We set all hint preferences to 'false' on the
+		// first pass through.
+		hints = append(hints, topologymanager.TopologyHint{
+			NUMANodeAffinity: mask,
+			Preferred:        false,
+		})
+	})
+
+	// Loop back through all hints and update the 'Preferred' field based on
+	// counting the number of bits sets in the affinity mask and comparing it
+	// to the minAffinitySize. Among the minimally sized hints, prefer only
+	// the ones whose sockets have the most RT headroom left, so that the
+	// TopologyManager spreads RT containers over the least loaded sockets.
+	maxHeadroom := float64(-1)
+	for i := range hints {
+		if hints[i].NUMANodeAffinity.Count() != minAffinitySize {
+			continue
+		}
+		sockets := p.topology.CPUDetails.SocketsInNUMANodes(hints[i].NUMANodeAffinity.GetBits()...)
+		if headroom := p.SocketHeadroom(cpuToUtil, sockets.ToSlice()...); headroom > maxHeadroom {
+			maxHeadroom = headroom
+		}
+	}
+	for i := range hints {
+		if hints[i].NUMANodeAffinity.Count() != minAffinitySize {
+			continue
+		}
+		sockets := p.topology.CPUDetails.SocketsInNUMANodes(hints[i].NUMANodeAffinity.GetBits()...)
+		if p.SocketHeadroom(cpuToUtil, sockets.ToSlice()...) >= maxHeadroom {
+			hints[i].Preferred = true
+		}
 	}
 
-	return fittingCpus
+	return hints
 }
 
-// worstFit assign the requests to the most free cpus.
-func (p *realTimePolicy) worstFit(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64) []int {
-	type scoredCpu struct {
-		cpu   int
-		score float64
+// SocketHeadroom returns the RT utilization still allocatable summed over the
+// cpus of the given sockets.
+func (p *realTimePolicy) SocketHeadroom(cpuToUtil map[int]float64, socketIDs ...int) float64 {
+	inSockets := p.topology.CPUDetails.CPUsInSockets(socketIDs...)
+	total := float64(0)
+	for cpu, util := range cpuToUtil {
+		if !inSockets.Contains(cpu) {
+			continue
+		}
+		if free := p.allocableRtUtil - util; free > 0 {
+			total += free
+		}
+	}
+	return total
+}
+
+// candidateCpus returns the cpu ids of cpuToUtil, sorted in ascending order
+// when the policy runs in deterministic fit mode.
+func (p *realTimePolicy) candidateCpus(cpuToUtil map[int]float64) []int {
+	cpus := make([]int, 0, len(cpuToUtil))
+	for cpu := range cpuToUtil {
+		cpus = append(cpus, cpu)
+	}
+	if p.deterministicFit {
+		sort.Ints(cpus)
 	}
+	return cpus
+}
 
+// scoredCpu pairs a cpu with the RT headroom it would retain after taking the
+// candidate request.
+type scoredCpu struct {
+	cpu   int
+	score float64
+}
+
+// scoreCpus returns the candidate cpus that can still take reqUtil, each
+// paired with the headroom it would retain, sorted by less when given and in
+// candidateCpus order otherwise. It is the scoring backbone shared by the fit
+// strategies.
+func (p *realTimePolicy) scoreCpus(cpuToUtil map[int]float64, reqUtil float64, less func(i, j scoredCpu) bool) []scoredCpu {
 	var scoredCpus []scoredCpu
-	for cpu, util := range cpuToUtil {
-		score := p.allocableRtUtil - util - reqUtil
+	for _, cpu := range p.candidateCpus(cpuToUtil) {
+		score := p.allocableRtUtil - cpuToUtil[cpu] - reqUtil
 		if score > 0 {
 			scoredCpus = append(scoredCpus, scoredCpu{
 				cpu:   cpu,
@@ -175,61 +573,230 @@ func (p *realTimePolicy) worstFit(cpuToUtil map[int]float64, reqUtil float64, re
 			})
 		}
 	}
+	if less != nil {
+		sort.SliceStable(scoredCpus, func(i, j int) bool {
+			return less(scoredCpus[i], scoredCpus[j])
+		})
+	}
+	return scoredCpus
+}
+
+// firstFit assign the requests to the first admittable cpus it find
+func (p *realTimePolicy) firstFit(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64) ([]int, error) {
+	if reqCpus <= 0 {
+		return nil, fmt.Errorf("requested cpus must be positive, got %d", reqCpus)
+	}
 
+	scoredCpus := p.scoreCpus(cpuToUtil, reqUtil, nil)
 	if int64(len(scoredCpus)) < reqCpus {
-		return nil
+		return nil, nil
 	}
 
-	sort.SliceStable(scoredCpus, func(i, j int) bool {
-		if scoredCpus[i].score > scoredCpus[j].score {
-			return true
-		}
-		return false
+	var fittingCpus []int
+	for i := int64(0); i < reqCpus; i++ {
+		fittingCpus = append(fittingCpus, scoredCpus[i].cpu)
+	}
+
+	return fittingCpus, nil
+}
+
+// worstFit assign the requests to the most free cpus.
+func (p *realTimePolicy) worstFit(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64) ([]int, error) {
+	if reqCpus <= 0 {
+		return nil, fmt.Errorf("requested cpus must be positive, got %d", reqCpus)
+	}
+
+	scoredCpus := p.scoreCpus(cpuToUtil, reqUtil, func(i, j scoredCpu) bool {
+		return i.score > j.score
 	})
+	if int64(len(scoredCpus)) < reqCpus {
+		return nil, nil
+	}
 
 	var fittingCpus []int
 	for i := int64(0); i < reqCpus; i++ {
 		fittingCpus = append(fittingCpus, scoredCpus[i].cpu)
 	}
 
-	return fittingCpus
+	return fittingCpus, nil
 }
 
-//
-func (p *realTimePolicy) bestFit(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64) []int {
-	type scoredCpu struct {
-		cpu   int
-		score float64
+// fitCpus dispatches the placement to the configured fit strategy. Without
+// complete topology detail the NUMA-aware strategies would be meaningless,
+// so flat topologies always fall back to worst fit.
+func (p *realTimePolicy) fitCpus(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64, preferred cpuset.CPUSet) ([]int, error) {
+	if p.fitStrategy == RtFitNumaBalanced && !p.flatTopology {
+		return p.numaBalancedFit(cpuToUtil, reqUtil, reqCpus, preferred)
 	}
+	return p.worstFitPreferring(cpuToUtil, reqUtil, reqCpus, preferred)
+}
 
-	var scoredCpus []scoredCpu
+// numaBalancedFit tries the NUMA nodes in descending order of aggregate RT
+// headroom and places the whole request on the first node that fits it,
+// applying worst fit among the cpus of that node. When no single node can
+// take the request it degrades to worst fit over all cpus.
+func (p *realTimePolicy) numaBalancedFit(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64, preferred cpuset.CPUSet) ([]int, error) {
+	if reqCpus <= 0 {
+		return nil, fmt.Errorf("requested cpus must be positive, got %d", reqCpus)
+	}
+
+	nodes := p.topology.CPUDetails.NUMANodes().ToSlice()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return p.numaHeadroom(cpuToUtil, nodes[i]) > p.numaHeadroom(cpuToUtil, nodes[j])
+	})
+
+	for _, node := range nodes {
+		inNode := p.topology.CPUDetails.CPUsInNUMANodes(node)
+		nodeCpuToUtil := make(map[int]float64)
+		for cpu, util := range cpuToUtil {
+			if inNode.Contains(cpu) {
+				nodeCpuToUtil[cpu] = util
+			}
+		}
+		cpus, err := p.worstFitPreferring(nodeCpuToUtil, reqUtil, reqCpus, preferred)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(cpus)) == reqCpus {
+			return cpus, nil
+		}
+	}
+
+	return p.worstFitPreferring(cpuToUtil, reqUtil, reqCpus, preferred)
+}
+
+// numaHeadroom returns the RT utilization still allocatable summed over the
+// cpus of the given NUMA node.
+func (p *realTimePolicy) numaHeadroom(cpuToUtil map[int]float64, nodeID int) float64 {
+	inNode := p.topology.CPUDetails.CPUsInNUMANodes(nodeID)
+	total := float64(0)
 	for cpu, util := range cpuToUtil {
-		score := p.allocableRtUtil - util - reqUtil
-		if score > 0 {
-			scoredCpus = append(scoredCpus, scoredCpu{
-				cpu:   cpu,
-				score: score,
-			})
+		if !inNode.Contains(cpu) {
+			continue
+		}
+		if free := p.allocableRtUtil - util; free > 0 {
+			total += free
 		}
 	}
+	return total
+}
 
-	if int64(len(scoredCpus)) < reqCpus {
-		return nil
+// worstFitPreferring ranks like worstFit but breaks headroom ties in favor
+// of the given cpus, so a restarted container drifts back toward the cpus of
+// its previous run even when only part of the old set is still usable.
+func (p *realTimePolicy) worstFitPreferring(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64, preferred cpuset.CPUSet) ([]int, error) {
+	if preferred.Size() == 0 {
+		return p.worstFit(cpuToUtil, reqUtil, reqCpus)
+	}
+	if reqCpus <= 0 {
+		return nil, fmt.Errorf("requested cpus must be positive, got %d", reqCpus)
 	}
 
-	sort.SliceStable(scoredCpus, func(i, j int) bool {
-		if scoredCpus[i].score < scoredCpus[j].score {
-			return true
+	scoredCpus := p.scoreCpus(cpuToUtil, reqUtil, func(i, j scoredCpu) bool {
+		if i.score != j.score {
+			return i.score > j.score
 		}
-		return false
+		return preferred.Contains(i.cpu) && !preferred.Contains(j.cpu)
+	})
+	if int64(len(scoredCpus)) < reqCpus {
+		return nil, nil
+	}
+
+	var fittingCpus []int
+	for i := int64(0); i < reqCpus; i++ {
+		fittingCpus = append(fittingCpus, scoredCpus[i].cpu)
+	}
+
+	return fittingCpus, nil
+}
+
+//
+func (p *realTimePolicy) bestFit(cpuToUtil map[int]float64, reqUtil float64, reqCpus int64) ([]int, error) {
+	if reqCpus <= 0 {
+		return nil, fmt.Errorf("requested cpus must be positive, got %d", reqCpus)
+	}
+
+	scoredCpus := p.scoreCpus(cpuToUtil, reqUtil, func(i, j scoredCpu) bool {
+		return i.score < j.score
 	})
+	if int64(len(scoredCpus)) < reqCpus {
+		return nil, nil
+	}
 
 	var fittingCpus []int
 	for i := int64(0); i < reqCpus; i++ {
 		fittingCpus = append(fittingCpus, scoredCpus[i].cpu)
 	}
 
-	return fittingCpus
+	return fittingCpus, nil
+}
+
+// headroom returns the RT utilization still allocatable summed over cpus.
+func (p *realTimePolicy) headroom(cpuToUtil map[int]float64) float64 {
+	total := float64(0)
+	for _, util := range cpuToUtil {
+		if free := p.allocableRtUtil - util; free > 0 {
+			total += free
+		}
+	}
+	return total
+}
+
+// CompatibleNodeRtPeriod computes a node level RT period compatible with the
+// given containers: the greatest common divisor of the containers' RT
+// periods, so that every container period is an integer multiple of the node
+// period. It also validates that each RT container declares a runtime not
+// exceeding its period. A zero return value means no container requests RT
+// resources.
+func CompatibleNodeRtPeriod(containers []v1.Container) (int64, error) {
+	nodePeriod := int64(0)
+	for i := range containers {
+		reqPeriod, reqRuntime, _ := rtRequests(&containers[i])
+		if reqPeriod == 0 && reqRuntime == 0 {
+			continue
+		}
+		if reqPeriod <= 0 {
+			return 0, fmt.Errorf("container %s declares rt runtime %d without a positive rt period", containers[i].Name, reqRuntime)
+		}
+		if reqRuntime < 0 || reqRuntime > reqPeriod {
+			return 0, fmt.Errorf("container %s declares rt runtime %d outside [0, %d]", containers[i].Name, reqRuntime, reqPeriod)
+		}
+		nodePeriod = gcd(nodePeriod, reqPeriod)
+	}
+	return nodePeriod, nil
+}
+
+//
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// PerSocketUtilization aggregates the per-cpu RT utilization of the given
+// assignment by socket.
+func (p *realTimePolicy) PerSocketUtilization(cpuToUtil map[int]float64) map[int]float64 {
+	perSocket := make(map[int]float64)
+	for cpu, util := range cpuToUtil {
+		perSocket[p.topology.CPUDetails[cpu].SocketID] += util
+	}
+	return perSocket
+}
+
+// podRequestsRtResources returns true if any container of the pod requests
+// real-time resources.
+func podRequestsRtResources(pod *v1.Pod) bool {
+	allContainers := []v1.Container{}
+	allContainers = append(allContainers, pod.Spec.InitContainers...)
+	allContainers = append(allContainers, pod.Spec.Containers...)
+	for idx := range allContainers {
+		reqPeriod, reqRuntime, reqCpus := rtRequests(&allContainers[idx])
+		if reqPeriod != 0 || reqRuntime != 0 || reqCpus != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 //
@@ -1,12 +1,21 @@
 package cpumanager
 
 import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
+	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager/bitmask"
+	"k8s.io/kubernetes/pkg/kubelet/leaky"
 	"testing"
 )
 
@@ -64,7 +73,7 @@ func Test_realTimePolicy_AddContainer(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := NewRealTimePolicy(tt.fields.topology, tt.fields.numReservedCpus, tt.fields.reservedCpus, tt.fields.allocableRtUtil)
+			p := NewRealTimePolicy(tt.fields.topology, tt.fields.numReservedCpus, tt.fields.reservedCpus, tt.fields.allocableRtUtil, 0)
 			p.Start(tt.args.s)
 			if err := p.AddContainer(tt.args.s, tt.args.pod, tt.args.container, tt.args.containerID); (err != nil) != tt.wantErr {
 				t.Errorf("AddContainer() error = %v, wantErr %v", err, tt.wantErr)
@@ -75,3 +84,1282 @@ func Test_realTimePolicy_AddContainer(t *testing.T) {
 		})
 	}
 }
+
+func Test_realTimePolicy_PauseContainerNotAccounted(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	pauseContainer := &v1.Container{
+		Name: leaky.PodInfraContainerName,
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+			},
+		},
+	}
+
+	if err := p.AddContainer(s, &v1.Pod{}, pauseContainer, "pause-id"); err != nil {
+		t.Fatalf("AddContainer() error = %v", err)
+	}
+	if _, ok := s.GetRtCPUSet("pause-id"); ok {
+		t.Errorf("AddContainer() assigned RT cpus to the pause container")
+	}
+	for cpu, util := range s.CpuToUtilMap() {
+		if util != 0 {
+			t.Errorf("AddContainer() accounted %f RT utilization on cpu %d for the pause container", util, cpu)
+		}
+	}
+}
+
+func Test_realTimePolicy_AddPodRollsBackOnFailure(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0).(*realTimePolicy)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	rtContainer := func(name string, runtime int64) v1.Container {
+		return v1.Container{
+			Name: name,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+					v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+					v1.ResourceRtCpu:     *resource.NewQuantity(8, resource.DecimalSI),
+				},
+			},
+		}
+	}
+	// the first container fits every cpu, the second pushes them all over
+	// the cap
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rt-pod"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				rtContainer("first", 500000),
+				rtContainer("second", 600000),
+			},
+		},
+	}
+	containerIDs := map[string]string{"first": "first-id", "second": "second-id"}
+
+	if err := p.AddPod(s, pod, containerIDs); err == nil {
+		t.Fatal("AddPod() admitted a pod whose second container does not fit")
+	}
+	// the first container's reservation must have been rolled back
+	if _, ok := s.GetRtCPUSet("first-id"); ok {
+		t.Error("AddPod() left the first container's reservation behind")
+	}
+	for cpu, util := range s.CpuToUtilMap() {
+		if util != 0 {
+			t.Errorf("AddPod() left %f RT utilization on cpu %d", util, cpu)
+		}
+	}
+
+	// the same pod without the oversized container is admitted whole
+	pod.Spec.Containers = pod.Spec.Containers[:1]
+	if err := p.AddPod(s, pod, containerIDs); err != nil {
+		t.Fatalf("AddPod() error = %v", err)
+	}
+	if _, ok := s.GetRtCPUSet("first-id"); !ok {
+		t.Error("AddPod() did not reserve the fitting container")
+	}
+}
+
+func Test_realTimePolicy_fitNonPositiveCpus(t *testing.T) {
+	p := &realTimePolicy{
+		topology:        topoDualSocketNoHT,
+		allocableRtUtil: 0.95,
+	}
+	cpuToUtil := map[int]float64{0: 0, 1: 0.5}
+
+	fitFuncs := map[string]func(map[int]float64, float64, int64) ([]int, error){
+		"firstFit": p.firstFit,
+		"worstFit": p.worstFit,
+		"bestFit":  p.bestFit,
+	}
+
+	for name, fit := range fitFuncs {
+		for _, reqCpus := range []int64{0, -1} {
+			cpus, err := fit(cpuToUtil, 0.1, reqCpus)
+			if err == nil {
+				t.Errorf("%s(reqCpus=%d) expected error, got none", name, reqCpus)
+			}
+			if cpus != nil {
+				t.Errorf("%s(reqCpus=%d) expected no cpus, got %v", name, reqCpus, cpus)
+			}
+		}
+	}
+}
+
+func Test_rtAdmissionSummary(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "rt-pod"}}
+	container := &v1.Container{Name: "rt-container"}
+
+	summary := rtAdmissionSummary(pod, container, cpuset.NewCPUSet(1, 3), 0.25, 1.65)
+
+	want := "[cpumanager] admitted RT container (pod: rt-pod, container: rt-container, requested util: 0.250, cpus: 1,3, node RT headroom: 1.650)"
+	if summary != want {
+		t.Errorf("rtAdmissionSummary() = %q, want %q", summary, want)
+	}
+}
+
+func Test_realTimePolicy_RtToNonRtTransition(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	rtContainer := &v1.Container{
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+			},
+		},
+	}
+
+	if err := p.AddContainer(s, &v1.Pod{}, rtContainer, "old-rt-container"); err != nil {
+		t.Fatalf("AddContainer() RT error = %v", err)
+	}
+
+	// the restart kills the old container, its utilization must be released
+	if err := p.RemoveContainer(s, "old-rt-container"); err != nil {
+		t.Fatalf("RemoveContainer() error = %v", err)
+	}
+
+	// the restarted container no longer requests RT resources
+	if err := p.AddContainer(s, &v1.Pod{}, &v1.Container{}, "new-container"); err != nil {
+		t.Fatalf("AddContainer() non RT error = %v", err)
+	}
+
+	for cpu, util := range s.CpuToUtilMap() {
+		if util != 0 {
+			t.Errorf("cpu %d keeps phantom utilization %f after RT to non-RT transition", cpu, util)
+		}
+	}
+}
+
+func Test_realTimePolicy_GetTopologyHintsMerge(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	container := v1.Container{
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(2, resource.DecimalSI),
+			},
+		},
+	}
+
+	rtHints := p.GetTopologyHints(s, v1.Pod{}, container)
+	if len(rtHints[string(v1.ResourceRtCpu)]) == 0 {
+		t.Fatalf("GetTopologyHints() returned no hints for an RT container")
+	}
+
+	// a stub provider that only accepts NUMA node 1
+	stubMask, _ := bitmask.NewBitMask(1)
+	stubHints := map[string][]topologymanager.TopologyHint{
+		"stub/resource": {{NUMANodeAffinity: stubMask, Preferred: true}},
+	}
+
+	numaNodes := topoDualSocketNoHT.CPUDetails.NUMANodes().ToSlice()
+	merged, admit := topologymanager.NewBestEffortPolicy(numaNodes).Merge([]map[string][]topologymanager.TopologyHint{rtHints, stubHints})
+	if !admit.Admit {
+		t.Fatalf("merge of RT hints with stub provider rejected admission: %v", admit.Message)
+	}
+
+	wantAffinity, _ := bitmask.NewBitMask(1)
+	if !merged.NUMANodeAffinity.IsEqual(wantAffinity) {
+		t.Errorf("merged affinity = %v, want %v", merged.NUMANodeAffinity, wantAffinity)
+	}
+}
+
+func Test_realTimePolicy_deterministicFit(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0).(*realTimePolicy)
+	p.deterministicFit = true
+
+	cpuToUtil := map[int]float64{0: 0.2, 1: 0.2, 2: 0.5, 3: 0.9}
+
+	// worstFit prefers the most free cpus, ties broken by lowest cpu id
+	cpus, err := p.worstFit(cpuToUtil, 0.1, 2)
+	if err != nil {
+		t.Fatalf("worstFit() error = %v", err)
+	}
+	if want := []int{0, 1}; !reflect.DeepEqual(cpus, want) {
+		t.Errorf("worstFit() = %v, want %v", cpus, want)
+	}
+
+	// firstFit scans cpus in ascending order
+	cpus, err = p.firstFit(cpuToUtil, 0.1, 2)
+	if err != nil {
+		t.Fatalf("firstFit() error = %v", err)
+	}
+	if want := []int{0, 1}; !reflect.DeepEqual(cpus, want) {
+		t.Errorf("firstFit() = %v, want %v", cpus, want)
+	}
+
+	// bestFit prefers the most loaded cpus that still fit
+	cpus, err = p.bestFit(cpuToUtil, 0.1, 2)
+	if err != nil {
+		t.Fatalf("bestFit() error = %v", err)
+	}
+	if want := []int{2, 0}; !reflect.DeepEqual(cpus, want) {
+		t.Errorf("bestFit() = %v, want %v", cpus, want)
+	}
+}
+
+func Test_realTimePolicy_NumaBalancedFit(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0).(*realTimePolicy)
+	p.fitStrategy = RtFitNumaBalanced
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	// load NUMA node 0: every one of its cpus still has more headroom than
+	// the cpus of node 1 would have after the placement, so plain worst fit
+	// would stay on node 0
+	s.SetRtCPUSetAndUtilOfContainer("resident", cpuset.NewCPUSet(0, 1, 2, 3), 0.1)
+
+	container := v1.Container{
+		Name: "rt-container",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(50000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(2, resource.DecimalSI),
+			},
+		},
+	}
+	if err := p.AddContainer(s, &v1.Pod{}, &container, "rt-container-id"); err != nil {
+		t.Fatalf("AddContainer() error = %v", err)
+	}
+
+	assigned, ok := s.GetRtCPUSet("rt-container-id")
+	if !ok {
+		t.Fatalf("container not assigned")
+	}
+	node1Cpus := topoDualSocketNoHT.CPUDetails.CPUsInNUMANodes(1)
+	if !assigned.IsSubsetOf(node1Cpus) {
+		t.Errorf("assigned cpus %s not on the less loaded NUMA node %s", assigned, node1Cpus)
+	}
+}
+
+func TestCompatibleNodeRtPeriod(t *testing.T) {
+	rtContainer := func(name string, runtime, period int64) v1.Container {
+		return v1.Container{
+			Name: name,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  *resource.NewQuantity(period, resource.DecimalSI),
+					v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		containers []v1.Container
+		want       int64
+		wantErr    bool
+	}{
+		{
+			name: "no RT containers",
+			containers: []v1.Container{
+				{Name: "plain"},
+			},
+			want: 0,
+		},
+		{
+			name: "harmonic periods give the minimum",
+			containers: []v1.Container{
+				rtContainer("a", 100000, 1000000),
+				rtContainer("b", 50000, 500000),
+				rtContainer("c", 25000, 250000),
+			},
+			want: 250000,
+		},
+		{
+			name: "non harmonic periods give the gcd",
+			containers: []v1.Container{
+				rtContainer("a", 100000, 1000000),
+				rtContainer("b", 30000, 300000),
+			},
+			want: 100000,
+		},
+		{
+			name: "runtime exceeding period is invalid",
+			containers: []v1.Container{
+				rtContainer("a", 2000000, 1000000),
+			},
+			wantErr: true,
+		},
+		{
+			name: "runtime without period is invalid",
+			containers: []v1.Container{
+				rtContainer("a", 100000, 0),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompatibleNodeRtPeriod(tt.containers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompatibleNodeRtPeriod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CompatibleNodeRtPeriod() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_realTimePolicy_RemoveContainerNoResidue(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	container := &v1.Container{
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(2, resource.DecimalSI),
+			},
+		},
+	}
+
+	if err := p.AddContainer(s, &v1.Pod{}, container, "rt-container"); err != nil {
+		t.Fatalf("AddContainer() error = %v", err)
+	}
+	if err := p.RemoveContainer(s, "rt-container"); err != nil {
+		t.Fatalf("RemoveContainer() error = %v", err)
+	}
+
+	if err := s.VerifyRelease("rt-container"); err != nil {
+		t.Errorf("VerifyRelease() after removal = %v, want nil", err)
+	}
+}
+
+func TestRtState_VerifyReleaseDetectsResidue(t *testing.T) {
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.NewCPUSet(0, 1),
+	})
+
+	s.SetRtCPUSetAndUtilOfContainer("rt-container", cpuset.NewCPUSet(0), 0.5)
+
+	// bypass RtState.Delete, dropping the cpuset while keeping the utilization
+	s.State.Delete("rt-container")
+
+	if err := s.VerifyRelease("rt-container"); err == nil {
+		t.Errorf("VerifyRelease() = nil, want residue error")
+	}
+}
+
+func TestRtState_GetRtContainers(t *testing.T) {
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.NewCPUSet(0, 1, 2, 3),
+	})
+
+	if got := s.GetRtContainers(); len(got) != 0 {
+		t.Fatalf("GetRtContainers() on empty state = %v, want empty", got)
+	}
+
+	s.SetRtCPUSetAndUtilOfContainer("rt-b", cpuset.NewCPUSet(1), 0.2)
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(0), 0.1)
+
+	if got, want := s.GetRtContainers(), []string{"rt-a", "rt-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRtContainers() = %v, want %v", got, want)
+	}
+
+	s.Delete("rt-a")
+
+	if got, want := s.GetRtContainers(), []string{"rt-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRtContainers() after delete = %v, want %v", got, want)
+	}
+}
+
+func Test_realTimePolicy_NodeUtilCeiling(t *testing.T) {
+	// per-cpu cap is generous, but the node-wide aggregate ceiling only
+	// leaves room for one of the two containers
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0.5)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	rtContainer := func(runtime int64) *v1.Container {
+		return &v1.Container{
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+					v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+					v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+				},
+			},
+		}
+	}
+
+	if err := p.AddContainer(s, &v1.Pod{}, rtContainer(300000), "rt-first"); err != nil {
+		t.Fatalf("AddContainer() below the ceiling error = %v", err)
+	}
+
+	// each cpu could still take 0.3 utilization, but the aggregate would
+	// reach 0.6 and cross the 0.5 ceiling
+	if err := p.AddContainer(s, &v1.Pod{}, rtContainer(300000), "rt-second"); err == nil {
+		t.Errorf("AddContainer() above the ceiling expected error, got none")
+	}
+}
+
+func Test_realTimePolicy_RestartReusesCpus(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "rt-pod-uid",
+		},
+	}
+	container := &v1.Container{
+		Name: "rt-container",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(2, resource.DecimalSI),
+			},
+		},
+	}
+
+	if err := p.AddContainer(s, pod, container, "restart-1"); err != nil {
+		t.Fatalf("AddContainer() error = %v", err)
+	}
+	firstSet, _, ok := s.GetRtCPUSetAndUtilOfContainer("restart-1")
+	if !ok {
+		t.Fatal("no RT assignment for first run")
+	}
+
+	// the container dies and comes back under a new id
+	if err := p.RemoveContainer(s, "restart-1"); err != nil {
+		t.Fatalf("RemoveContainer() error = %v", err)
+	}
+	if err := p.AddContainer(s, pod, container, "restart-2"); err != nil {
+		t.Fatalf("AddContainer() after restart error = %v", err)
+	}
+
+	secondSet, _, ok := s.GetRtCPUSetAndUtilOfContainer("restart-2")
+	if !ok {
+		t.Fatal("no RT assignment after restart")
+	}
+	if !firstSet.Equals(secondSet) {
+		t.Errorf("restarted container got cpus %s, want previous cpus %s", secondSet, firstSet)
+	}
+}
+
+func Test_realTimePolicy_PartialTopologyDetail(t *testing.T) {
+	// cpu 3 is known to the machine but has no socket/core/NUMA detail
+	partialTopo := &topology.CPUTopology{
+		NumCPUs:    4,
+		NumSockets: 2,
+		NumCores:   4,
+		CPUDetails: map[int]topology.CPUInfo{
+			0: {CoreID: 0, SocketID: 0, NUMANodeID: 0},
+			1: {CoreID: 1, SocketID: 0, NUMANodeID: 0},
+			2: {CoreID: 2, SocketID: 1, NUMANodeID: 1},
+		},
+	}
+
+	p := NewRealTimePolicy(partialTopo, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	pod := v1.Pod{}
+	container := v1.Container{
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+			},
+		},
+	}
+
+	if hints := p.GetTopologyHints(s, pod, container); hints != nil {
+		t.Errorf("GetTopologyHints() with partial topology = %v, want nil", hints)
+	}
+
+	// flat allocation still works
+	if err := p.AddContainer(s, &pod, &container, "flat-rt"); err != nil {
+		t.Errorf("AddContainer() with partial topology error = %v", err)
+	}
+
+	// a complete topology keeps producing hints
+	p = NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s = state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+	if hints := p.GetTopologyHints(s, pod, container); hints == nil {
+		t.Error("GetTopologyHints() with complete topology = nil, want hints")
+	}
+}
+
+func Test_realTimePolicy_Validate(t *testing.T) {
+	rtRequests := func(runtime, period, cpus int64) v1.ResourceRequirements {
+		return v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(period, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(cpus, resource.DecimalSI),
+			},
+		}
+	}
+	podWith := func(resources v1.ResourceRequirements) *v1.Pod {
+		return &v1.Pod{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "rt", Resources: resources}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pod     *v1.Pod
+		wantErr bool
+	}{
+		{
+			name:    "non RT pod",
+			pod:     podWith(v1.ResourceRequirements{}),
+			wantErr: false,
+		},
+		{
+			name:    "valid RT request",
+			pod:     podWith(rtRequests(100000, 1000000, 2)),
+			wantErr: false,
+		},
+		{
+			name:    "runtime exceeds period",
+			pod:     podWith(rtRequests(1000000, 100000, 1)),
+			wantErr: true,
+		},
+		{
+			name:    "runtime without cpus",
+			pod:     podWith(rtRequests(100000, 1000000, 0)),
+			wantErr: true,
+		},
+		{
+			name:    "utilization above the per-cpu cap",
+			pod:     podWith(rtRequests(990000, 1000000, 1)),
+			wantErr: true,
+		},
+	}
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Validate(tt.pod); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_realTimePolicy_HintsPreferSocketWithMoreHeadroom(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0).(*realTimePolicy)
+
+	// both sockets can take the request, but socket 0 is already loaded so
+	// socket 1 has the larger headroom
+	cpuToUtil := map[int]float64{
+		0: 0.5, 1: 0.5, 2: 0.5, 3: 0.5,
+		4: 0, 5: 0, 6: 0, 7: 0,
+	}
+
+	hints := p.generateRtTopologyHints(cpuset.NewCPUSet(0, 1, 2, 3, 4, 5, 6, 7), cpuToUtil, 2)
+
+	socket0Mask, _ := bitmask.NewBitMask(0)
+	socket1Mask, _ := bitmask.NewBitMask(1)
+	for _, hint := range hints {
+		switch {
+		case hint.NUMANodeAffinity.IsEqual(socket0Mask):
+			if hint.Preferred {
+				t.Errorf("hint for the loaded socket %v is preferred, want not preferred", hint.NUMANodeAffinity)
+			}
+		case hint.NUMANodeAffinity.IsEqual(socket1Mask):
+			if !hint.Preferred {
+				t.Errorf("hint for the socket with more headroom %v is not preferred", hint.NUMANodeAffinity)
+			}
+		}
+	}
+}
+
+func TestRtState_CleanOrphanedUtil(t *testing.T) {
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.NewCPUSet(0, 1, 2, 3),
+	})
+
+	s.SetRtCPUSetAndUtilOfContainer("rt-kept", cpuset.NewCPUSet(0, 1), 0.2)
+	s.SetRtCPUSetAndUtilOfContainer("rt-orphan", cpuset.NewCPUSet(2), 0.5)
+
+	// bypass RtState.Delete, dropping the cpuset while keeping the utilization
+	s.State.Delete("rt-orphan")
+
+	if got, want := s.CleanOrphanedUtil(), []string{"rt-orphan"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("CleanOrphanedUtil() = %v, want %v", got, want)
+	}
+
+	if got, want := s.GetRtContainers(), []string{"rt-kept"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRtContainers() after cleanup = %v, want %v", got, want)
+	}
+
+	wantUtil := map[int]float64{0: 0.2, 1: 0.2, 2: 0, 3: 0}
+	if got := s.CpuToUtilMap(); !reflect.DeepEqual(got, wantUtil) {
+		t.Errorf("CpuToUtilMap() after cleanup = %v, want %v", got, wantUtil)
+	}
+
+	// a clean state reports nothing to remove
+	if got := s.CleanOrphanedUtil(); got != nil {
+		t.Errorf("CleanOrphanedUtil() on clean state = %v, want nil", got)
+	}
+}
+
+func TestRtState_LoadAssignments(t *testing.T) {
+	newState := func() *state.RtState {
+		return state.NewRtState(&mockState{
+			assignments:   make(state.ContainerCPUAssignments),
+			defaultCPUSet: cpuset.NewCPUSet(0, 1, 2, 3),
+		})
+	}
+
+	t.Run("valid input replaces the state", func(t *testing.T) {
+		s := newState()
+		s.SetRtCPUSetAndUtilOfContainer("stale", cpuset.NewCPUSet(3), 0.9)
+
+		err := s.LoadAssignments(
+			map[string]cpuset.CPUSet{
+				"rt-a": cpuset.NewCPUSet(0, 1),
+				"rt-b": cpuset.NewCPUSet(1),
+			},
+			map[string]float64{
+				"rt-a": 0.2,
+				"rt-b": 0.3,
+			},
+		)
+		if err != nil {
+			t.Fatalf("LoadAssignments() error = %v", err)
+		}
+
+		if got, want := s.GetRtContainers(), []string{"rt-a", "rt-b"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("GetRtContainers() = %v, want %v", got, want)
+		}
+		wantUtil := map[int]float64{0: 0.2, 1: 0.5, 2: 0, 3: 0}
+		if got := s.CpuToUtilMap(); !reflect.DeepEqual(got, wantUtil) {
+			t.Errorf("CpuToUtilMap() = %v, want %v", got, wantUtil)
+		}
+	})
+
+	t.Run("over-cap input is rejected untouched", func(t *testing.T) {
+		s := newState()
+		s.SetRtCPUSetAndUtilOfContainer("kept", cpuset.NewCPUSet(0), 0.1)
+
+		err := s.LoadAssignments(
+			map[string]cpuset.CPUSet{
+				"rt-a": cpuset.NewCPUSet(2),
+				"rt-b": cpuset.NewCPUSet(2),
+			},
+			map[string]float64{
+				"rt-a": 0.6,
+				"rt-b": 0.6,
+			},
+		)
+		if err == nil {
+			t.Fatal("LoadAssignments() = nil, want over-cap error")
+		}
+
+		if got, want := s.GetRtContainers(), []string{"kept"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("GetRtContainers() after rejected load = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("utilization without cpuset is rejected", func(t *testing.T) {
+		s := newState()
+		err := s.LoadAssignments(
+			map[string]cpuset.CPUSet{},
+			map[string]float64{"rt-a": 0.2},
+		)
+		if err == nil {
+			t.Error("LoadAssignments() = nil, want missing cpuset error")
+		}
+	})
+}
+
+func TestNewRtStateWithAssignableCPUs(t *testing.T) {
+	// the checkpoint narrowed the default set to cpus 0-1, the bookkeeping
+	// still has to cover every RT-eligible cpu
+	s := state.NewRtStateWithAssignableCPUs(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.NewCPUSet(0, 1),
+	}, cpuset.NewCPUSet(0, 1, 2, 3))
+
+	wantUtil := map[int]float64{0: 0, 1: 0, 2: 0, 3: 0}
+	if got := s.CpuToUtilMap(); !reflect.DeepEqual(got, wantUtil) {
+		t.Errorf("CpuToUtilMap() = %v, want %v", got, wantUtil)
+	}
+
+	// resetting the default set keeps the explicit coverage
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2))
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(3), 0.2)
+	wantUtil = map[int]float64{0: 0, 1: 0, 2: 0, 3: 0.2}
+	if got := s.CpuToUtilMap(); !reflect.DeepEqual(got, wantUtil) {
+		t.Errorf("CpuToUtilMap() after SetDefaultCPUSet = %v, want %v", got, wantUtil)
+	}
+
+	// assignments outside the explicit set are rejected on load
+	err := s.LoadAssignments(
+		map[string]cpuset.CPUSet{"rt-b": cpuset.NewCPUSet(5)},
+		map[string]float64{"rt-b": 0.1},
+	)
+	if err == nil {
+		t.Error("LoadAssignments() = nil, want out of assignable set error")
+	}
+}
+
+func Test_realTimePolicy_PerSocketUtilization(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0).(*realTimePolicy)
+
+	// cpus 0-3 sit on socket 0, cpus 4-7 on socket 1
+	cpuToUtil := map[int]float64{
+		0: 0.3,
+		1: 0.2,
+		4: 0.1,
+	}
+
+	got := p.PerSocketUtilization(cpuToUtil)
+	want := map[int]float64{0: 0.5, 1: 0.1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PerSocketUtilization() = %v, want %v", got, want)
+	}
+
+	if got := p.PerSocketUtilization(nil); len(got) != 0 {
+		t.Errorf("PerSocketUtilization(nil) = %v, want empty", got)
+	}
+}
+
+func TestRtState_AllocationEvents(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+
+	var events []state.RtAllocationEvent
+	s.Subscribe(func(event state.RtAllocationEvent) {
+		events = append(events, event)
+	})
+
+	s.SetRtCPUSetAndUtilOfContainer("rt-container", cpuset.NewCPUSet(0), 0.2)
+	s.SetRtCPUSetAndUtilOfContainer("rt-container", cpuset.NewCPUSet(0, 1), 0.4)
+	s.Delete("rt-container")
+
+	want := []state.RtAllocationEvent{
+		{ContainerID: "rt-container", CPUSet: cpuset.NewCPUSet(0), Util: 0.2, Action: state.RtAllocationAdded},
+		{ContainerID: "rt-container", CPUSet: cpuset.NewCPUSet(0, 1), Util: 0.4, Action: state.RtAllocationUpdated},
+		{ContainerID: "rt-container", CPUSet: cpuset.NewCPUSet(0, 1), Util: 0.4, Action: state.RtAllocationRemoved},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("allocation events = %v, want %v", events, want)
+	}
+}
+
+func Test_realTimePolicy_ExclusiveMode(t *testing.T) {
+	p := NewExclusiveRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	s := state.NewRtState(state.NewMemoryState())
+	p.Start(s)
+
+	makeRtPod := func(name string) (*v1.Pod, *v1.Container) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "rt",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+								v1.ResourceRtRuntime: *resource.NewQuantity(10000, resource.DecimalSI),
+								v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		}
+		return pod, &pod.Spec.Containers[0]
+	}
+
+	// the topology has 8 cpus: with one cpu per container, 8 tiny containers
+	// fill the node despite the utilization headroom left on every cpu
+	occupied := cpuset.NewCPUSet()
+	for i := 0; i < 8; i++ {
+		containerID := fmt.Sprintf("rt-%d", i)
+		pod, container := makeRtPod(containerID)
+		if err := p.AddContainer(s, pod, container, containerID); err != nil {
+			t.Fatalf("AddContainer(%s) error = %v", containerID, err)
+		}
+		set, _, _ := s.GetRtCPUSetAndUtilOfContainer(containerID)
+		if !occupied.Intersection(set).IsEmpty() {
+			t.Fatalf("container %s shares cpus %s with a previous RT container", containerID, occupied.Intersection(set))
+		}
+		occupied = occupied.Union(set)
+	}
+
+	pod, container := makeRtPod("rt-overflow")
+	if err := p.AddContainer(s, pod, container, "rt-overflow"); err == nil {
+		t.Error("AddContainer() on a full exclusive node = nil, want error")
+	}
+}
+
+func Test_realTimePolicy_StartPreservesAssignments(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3, 4, 5, 6, 7))
+	s.SetRtCPUSetAndUtilOfContainer("rt-kept", cpuset.NewCPUSet(1), 0.2)
+	s.SetRtCPUSetAndUtilOfContainer("rt-foreign", cpuset.NewCPUSet(42), 0.2)
+	// a cpuset without tracked utilization cannot be accounted, it must not
+	// survive Start
+	s.SetCPUSet("untracked", cpuset.NewCPUSet(2))
+
+	policy := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+	policy.Start(s)
+
+	set, util, ok := s.GetRtCPUSetAndUtilOfContainer("rt-kept")
+	if !ok || util != 0.2 || !set.Equals(cpuset.NewCPUSet(1)) {
+		t.Errorf("Start() kept cpus %s util %f (assigned %t), want cpu 1 with util 0.2", set, util, ok)
+	}
+	if _, ok := s.GetCPUSet("rt-foreign"); ok {
+		t.Error("Start() preserved an assignment on cpus outside the machine")
+	}
+	if _, ok := s.GetCPUSet("untracked"); ok {
+		t.Error("Start() preserved an assignment without tracked utilization")
+	}
+	if got := s.CpuToUtilMap(); got[1] != 0.2 || got[2] != 0 {
+		t.Errorf("Start() rebuilt cpuToUtil = %v, want 0.2 on cpu 1 only", got)
+	}
+}
+
+func Test_realTimePolicy_ValidateRejectsRtEphemeralContainers(t *testing.T) {
+	podWithEphemeral := func(resources v1.ResourceRequirements) *v1.Pod {
+		return &v1.Pod{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "app"}},
+				EphemeralContainers: []v1.EphemeralContainer{
+					{
+						EphemeralContainerCommon: v1.EphemeralContainerCommon{
+							Name:      "debug",
+							Resources: resources,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0)
+
+	if err := p.Validate(podWithEphemeral(v1.ResourceRequirements{})); err != nil {
+		t.Errorf("Validate() with a non RT ephemeral container error = %v, want nil", err)
+	}
+
+	rtResources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+			v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+			v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+		},
+	}
+	err := p.Validate(podWithEphemeral(rtResources))
+	if err == nil {
+		t.Fatal("Validate() with an RT ephemeral container = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "ephemeral") {
+		t.Errorf("Validate() error = %v, want it to name the ephemeral container", err)
+	}
+}
+
+func Test_realTimePolicy_ScoreCpus(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0).(*realTimePolicy)
+	p.deterministicFit = true
+
+	// cpu 2 cannot take another 0.2 of utilization and must not be scored
+	cpuToUtil := map[int]float64{0: 0.5, 1: 0.1, 2: 0.9, 3: 0.3}
+	reqUtil := 0.2
+
+	cpusOf := func(scored []scoredCpu) []int {
+		cpus := make([]int, 0, len(scored))
+		for _, sc := range scored {
+			cpus = append(cpus, sc.cpu)
+		}
+		return cpus
+	}
+
+	unsorted := p.scoreCpus(cpuToUtil, reqUtil, nil)
+	if got, want := cpusOf(unsorted), []int{0, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scoreCpus() candidates = %v, want %v", got, want)
+	}
+	for _, sc := range unsorted {
+		if want := p.allocableRtUtil - cpuToUtil[sc.cpu] - reqUtil; sc.score != want {
+			t.Errorf("scoreCpus() score of cpu %d = %f, want %f", sc.cpu, sc.score, want)
+		}
+	}
+
+	descending := p.scoreCpus(cpuToUtil, reqUtil, func(i, j scoredCpu) bool {
+		return i.score > j.score
+	})
+	if got, want := cpusOf(descending), []int{1, 3, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("scoreCpus() descending candidates = %v, want %v", got, want)
+	}
+
+	ascending := p.scoreCpus(cpuToUtil, reqUtil, func(i, j scoredCpu) bool {
+		return i.score < j.score
+	})
+	if got, want := cpusOf(ascending), []int{0, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("scoreCpus() ascending candidates = %v, want %v", got, want)
+	}
+}
+
+func TestRtStateCpusetAccessors(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+	s.SetRtCPUSetAndUtilOfContainer("rt-id", cpuset.NewCPUSet(1, 2), 0.3)
+
+	cpus, util, ok := s.GetRtCPUSetAndUtilOfContainer("rt-id")
+	if !ok || !cpus.Equals(cpuset.NewCPUSet(1, 2)) || util != 0.3 {
+		t.Errorf("GetRtCPUSetAndUtilOfContainer() = (%s, %f, %t), want (1-2, 0.3, true)", cpus, util, ok)
+	}
+
+	cpus, ok = s.GetRtCPUSet("rt-id")
+	if !ok || !cpus.Equals(cpuset.NewCPUSet(1, 2)) {
+		t.Errorf("GetRtCPUSet() = (%s, %t), want (1-2, true)", cpus, ok)
+	}
+
+	if _, ok := s.GetRtCPUSet("unknown"); ok {
+		t.Error("GetRtCPUSet() = true for an untracked container")
+	}
+
+	// a plain cpuset without a tracked utilization is not an RT assignment
+	s.SetCPUSet("cpuset-only", cpuset.NewCPUSet(3))
+	if _, ok := s.GetRtCPUSet("cpuset-only"); ok {
+		t.Error("GetRtCPUSet() = true for a container without tracked utilization")
+	}
+}
+
+func TestValidateRtReservedCPUs(t *testing.T) {
+	tests := []struct {
+		name      string
+		reserved  cpuset.CPUSet
+		expectErr bool
+	}{
+		{
+			name:      "no explicit reservation",
+			reserved:  cpuset.NewCPUSet(),
+			expectErr: false,
+		},
+		{
+			name:      "valid reservation",
+			reserved:  cpuset.NewCPUSet(0, 4),
+			expectErr: false,
+		},
+		{
+			name:      "out-of-range cpu id",
+			reserved:  cpuset.NewCPUSet(0, 42),
+			expectErr: true,
+		},
+		{
+			name:      "every cpu reserved",
+			reserved:  cpuset.NewCPUSet(0, 1, 2, 3, 4, 5, 6, 7),
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateRtReservedCPUs(topoDualSocketNoHT, test.reserved)
+			if gotErr := err != nil; gotErr != test.expectErr {
+				t.Errorf("validateRtReservedCPUs(%s) error = %v, expectErr = %t", test.reserved, err, test.expectErr)
+			}
+		})
+	}
+}
+
+func TestRtStateMaxFittingUtilization(t *testing.T) {
+	// a state without a default cpuset tracks no cpus
+	empty := state.NewRtState(state.NewMemoryState())
+	if got := empty.MaxFittingUtilization(0.95); got != 0 {
+		t.Errorf("MaxFittingUtilization() = %f on an empty state, want 0", got)
+	}
+
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+
+	// all cpus free: the full per-cpu cap fits
+	if got := s.MaxFittingUtilization(0.95); got != 0.95 {
+		t.Errorf("MaxFittingUtilization() = %f on a free state, want 0.95", got)
+	}
+
+	// partially loaded: the freest cpu decides
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(0, 1), 0.5)
+	s.SetRtCPUSetAndUtilOfContainer("rt-b", cpuset.NewCPUSet(2), 0.2)
+	if got, want := s.MaxFittingUtilization(0.95), 0.95; got != want {
+		t.Errorf("MaxFittingUtilization() = %f with cpu 3 free, want %f", got, want)
+	}
+	s.SetRtCPUSetAndUtilOfContainer("rt-c", cpuset.NewCPUSet(3), 0.9)
+	if got, want := s.MaxFittingUtilization(0.95), 0.95-0.2; got != want {
+		t.Errorf("MaxFittingUtilization() = %f on a loaded state, want %f", got, want)
+	}
+
+	// every cpu at or over the cap leaves nothing placeable
+	if got := s.MaxFittingUtilization(0.2); got != 0 {
+		t.Errorf("MaxFittingUtilization() = %f on a saturated state, want 0", got)
+	}
+}
+
+func TestRtStateCanAccommodate(t *testing.T) {
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.NewCPUSet(0, 1, 2, 3),
+	})
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(1), 0.5)
+
+	// every cpu of the set has headroom left for the additional utilization
+	if !s.CanAccommodate(cpuset.NewCPUSet(1, 2), 0.4, 0.95) {
+		t.Error("CanAccommodate() = false for a fitting set, want true")
+	}
+
+	// cpu 1 would end up over the cap
+	if s.CanAccommodate(cpuset.NewCPUSet(1, 2), 0.5, 0.95) {
+		t.Error("CanAccommodate() = true for an over-committing set, want false")
+	}
+
+	// untracked cpus offer no headroom
+	if s.CanAccommodate(cpuset.NewCPUSet(7), 0.1, 0.95) {
+		t.Error("CanAccommodate() = true for an untracked cpu, want false")
+	}
+}
+
+func TestRtStateAggregateRtRuntimesRounding(t *testing.T) {
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.NewCPUSet(1),
+	})
+
+	// seven containers of utilization 1/7 share cpu 1; rounding each one up
+	// to a whole microsecond would write 7 * 142858 = 1000006, past the
+	// period
+	const period = int64(1000000)
+	for i := 0; i < 7; i++ {
+		s.SetRtCPUSetAndUtilOfContainer(fmt.Sprintf("rt-%d", i), cpuset.NewCPUSet(1), 1.0/7.0)
+	}
+
+	runtimes := s.AggregateRtRuntimes(cpuset.NewCPUSet(1), period)
+	if len(runtimes) != 1 {
+		t.Fatalf("AggregateRtRuntimes() returned %d runtimes, want 1", len(runtimes))
+	}
+	if runtimes[0] > period {
+		t.Errorf("AggregateRtRuntimes() = %d, exceeds the period %d", runtimes[0], period)
+	}
+	// the floor of the aggregate loses less than a microsecond in total
+	if runtimes[0] < period-1 {
+		t.Errorf("AggregateRtRuntimes() = %d, want at least %d", runtimes[0], period-1)
+	}
+}
+
+func TestRtStateOverCommittedCPUs(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+
+	// a correctly loaded state reports nothing
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(0, 1), 0.5)
+	if got := s.OverCommittedCPUs(0.95); got != nil {
+		t.Errorf("OverCommittedCPUs() = %v on a correctly loaded state, want none", got)
+	}
+
+	// seed an over-committed cpu: two containers stacking past the cap on cpu 1
+	s.SetRtCPUSetAndUtilOfContainer("rt-b", cpuset.NewCPUSet(1, 2), 0.6)
+	if got, want := s.OverCommittedCPUs(0.95), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OverCommittedCPUs() = %v, want %v", got, want)
+	}
+
+	// a cpu exactly at the cap is not over-committed
+	if got, want := s.OverCommittedCPUs(0.6), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("OverCommittedCPUs() = %v with the cap at 0.6, want %v", got, want)
+	}
+}
+
+func TestRtStateUtilizationVariance(t *testing.T) {
+	// a state without tracked cpus has no spread to measure
+	empty := state.NewRtState(state.NewMemoryState())
+	if got := empty.UtilizationVariance(); got != 0 {
+		t.Errorf("UtilizationVariance() = %f on an empty state, want 0", got)
+	}
+
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+
+	// a balanced placement has zero variance
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(0, 1, 2, 3), 0.25)
+	if got := s.UtilizationVariance(); got != 0 {
+		t.Errorf("UtilizationVariance() = %f on a balanced state, want 0", got)
+	}
+
+	// piling the load on half the cpus: utilizations 0.75, 0.75, 0.25, 0.25
+	// have mean 0.5 and variance 0.0625
+	s.SetRtCPUSetAndUtilOfContainer("rt-b", cpuset.NewCPUSet(0, 1), 0.5)
+	if got, want := s.UtilizationVariance(), 0.0625; math.Abs(got-want) > 1e-9 {
+		t.Errorf("UtilizationVariance() = %f on an unbalanced state, want %f", got, want)
+	}
+}
+
+func TestRtStateRename(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+
+	s.SetRtCPUSetAndUtilOfContainer("rt-old", cpuset.NewCPUSet(1, 2), 0.3)
+	s.SetRtCPUSetAndUtilOfContainer("rt-b", cpuset.NewCPUSet(2), 0.2)
+
+	if err := s.Rename("rt-old", "rt-new"); err != nil {
+		t.Fatalf("Rename() failed: %v", err)
+	}
+
+	// the assignment carries over untouched under the new id
+	set, util, ok := s.GetRtCPUSetAndUtilOfContainer("rt-new")
+	if !ok {
+		t.Fatal("Rename() left no RT assignment under the new id")
+	}
+	if !set.Equals(cpuset.NewCPUSet(1, 2)) || util != 0.3 {
+		t.Errorf("Rename() moved cpus %s util %f, want cpus 1-2 util 0.3", set, util)
+	}
+	if err := s.VerifyRelease("rt-old"); err != nil {
+		t.Errorf("Rename() left residue under the old id: %v", err)
+	}
+
+	// the per-cpu accounting is unaffected
+	want := map[int]float64{0: 0, 1: 0.3, 2: 0.5, 3: 0}
+	if got := s.CpuToUtilMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("per-cpu utilization after Rename() = %v, want %v", got, want)
+	}
+
+	// an id without an assignment cannot be renamed, nor can one be renamed
+	// onto an id already holding one
+	if err := s.Rename("rt-old", "rt-other"); err == nil {
+		t.Error("Rename() of a released id did not fail")
+	}
+	if err := s.Rename("rt-new", "rt-b"); err == nil {
+		t.Error("Rename() onto an id holding an assignment did not fail")
+	}
+}
+
+func TestRtStateNodeCPUUtil(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3, 4, 5, 6, 7))
+
+	s.SetRtCPUSetAndUtilOfContainer("rt-a", cpuset.NewCPUSet(0, 1), 0.3)
+	s.SetRtCPUSetAndUtilOfContainer("rt-b", cpuset.NewCPUSet(1, 4), 0.2)
+
+	// NUMA node 0 holds cpus 0-3, with rt-a and rt-b stacking on cpu 1
+	got := s.NodeCPUUtil(topoDualSocketNoHT, 0)
+	want := map[int]float64{0: 0.3, 1: 0.5, 2: 0, 3: 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NodeCPUUtil(node 0) = %v, want %v", got, want)
+	}
+
+	// NUMA node 1 holds cpus 4-7, only cpu 4 is loaded
+	got = s.NodeCPUUtil(topoDualSocketNoHT, 1)
+	want = map[int]float64{4: 0.2, 5: 0, 6: 0, 7: 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NodeCPUUtil(node 1) = %v, want %v", got, want)
+	}
+}
+
+func Test_realTimePolicy_RestartPartialCpuReuse(t *testing.T) {
+	p := NewRealTimePolicy(topoDualSocketNoHT, 0, cpuset.NewCPUSet(), 0.95, 0).(*realTimePolicy)
+	s := state.NewRtState(&mockState{
+		assignments:   make(state.ContainerCPUAssignments),
+		defaultCPUSet: cpuset.CPUSet{},
+	})
+	p.Start(s)
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "rt-pod-uid",
+		},
+	}
+	container := &v1.Container{
+		Name: "rt-container",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(300000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(2, resource.DecimalSI),
+			},
+		},
+	}
+
+	if err := p.AddContainer(s, pod, container, "restart-1"); err != nil {
+		t.Fatalf("AddContainer() error = %v", err)
+	}
+	firstSet, _, ok := s.GetRtCPUSetAndUtilOfContainer("restart-1")
+	if !ok {
+		t.Fatal("no RT assignment for first run")
+	}
+	if err := p.RemoveContainer(s, "restart-1"); err != nil {
+		t.Fatalf("RemoveContainer() error = %v", err)
+	}
+
+	// one of the previous cpus fills up while the container is down, so the
+	// old set can no longer be reused as a whole
+	firstCpus := firstSet.ToSlice()
+	s.SetRtCPUSetAndUtilOfContainer("squatter", cpuset.NewCPUSet(firstCpus[0]), 0.9)
+
+	if err := p.AddContainer(s, pod, container, "restart-2"); err != nil {
+		t.Fatalf("AddContainer() after restart error = %v", err)
+	}
+	secondSet, _, ok := s.GetRtCPUSetAndUtilOfContainer("restart-2")
+	if !ok {
+		t.Fatal("no RT assignment after restart")
+	}
+	if secondSet.Contains(firstCpus[0]) {
+		t.Errorf("restarted container got the saturated cpu %d: %s", firstCpus[0], secondSet)
+	}
+	if !secondSet.Contains(firstCpus[1]) {
+		t.Errorf("restarted container got cpus %s, want the still-free previous cpu %d reused", secondSet, firstCpus[1])
+	}
+}
@@ -187,6 +187,13 @@ func (p *staticPolicy) assignableCPUs(s state.State) cpuset.CPUSet {
 	return s.GetDefaultCPUSet().Difference(p.reserved)
 }
 
+func (p *staticPolicy) Validate(pod *v1.Pod) error {
+	if podRequestsRtResources(pod) {
+		klog.Warningf("[cpumanager] static policy: pod %s requests real-time resources, but the active policy does not enforce them; the pod will run without RT guarantees", pod.Name)
+	}
+	return nil
+}
+
 func (p *staticPolicy) AddContainer(s state.State, pod *v1.Pod, container *v1.Container, containerID string) (rerr error) {
 	// So long as this function does not return an error,
 	// add (pod, container, containerID) to the containerMap.
@@ -17,11 +17,16 @@ limitations under the License.
 package cpumanager
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
@@ -1005,3 +1010,28 @@ func TestStaticPolicyAddWithResvList(t *testing.T) {
 		}
 	}
 }
+
+func TestStaticPolicyWarnsOnRtRequests(t *testing.T) {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	fs.Set("logtostderr", "false")
+	defer fs.Set("logtostderr", "true")
+	var buf bytes.Buffer
+	klog.SetOutput(&buf)
+
+	policy := NewStaticPolicy(topoSingleSocketHT, 1, cpuset.NewCPUSet(), topologymanager.NewFakeManager())
+
+	pod := makePod("1000m", "1000m")
+	pod.Name = "rt-on-static"
+	pod.Spec.Containers[0].Resources.Requests[v1.ResourceRtPeriod] = *resource.NewQuantity(1000000, resource.DecimalSI)
+	pod.Spec.Containers[0].Resources.Requests[v1.ResourceRtRuntime] = *resource.NewQuantity(100000, resource.DecimalSI)
+	pod.Spec.Containers[0].Resources.Requests[v1.ResourceRtCpu] = *resource.NewQuantity(1, resource.DecimalSI)
+
+	if err := policy.Validate(pod); err != nil {
+		t.Fatalf("Validate() error = %v, the static policy must not reject RT pods", err)
+	}
+	klog.Flush()
+	if !strings.Contains(buf.String(), "without RT guarantees") {
+		t.Errorf("Validate() did not warn about unenforced RT resources, log: %q", buf.String())
+	}
+}
@@ -0,0 +1,78 @@
+package cpumanager
+
+import (
+	"sync"
+	"time"
+)
+
+// rtStartGateSettle is how long an RT container start waits for the starts
+// racing with it to register, so the gate orders the whole batch instead of
+// following the arrival order.
+const rtStartGateSettle = 100 * time.Millisecond
+
+// rtStartGate serializes the RT container starts racing through
+// AddContainer. After a node reboot many RT pods sync concurrently and the
+// resulting placement depends on the order the cpu manager sees them:
+// admitting the largest utilizations first packs the cpus like a descending
+// first-fit and leaves the least fragmentation for the rest of the batch.
+type rtStartGate struct {
+	sync.Mutex
+	cond    *sync.Cond
+	settle  time.Duration
+	pending map[string]float64
+	running bool
+}
+
+func newRtStartGate(settle time.Duration) *rtStartGate {
+	g := &rtStartGate{
+		settle:  settle,
+		pending: make(map[string]float64),
+	}
+	g.cond = sync.NewCond(&g.Mutex)
+	return g
+}
+
+// Enter registers the start of containerID with the given RT utilization and
+// blocks until no registered start with a higher utilization is waiting and
+// no start is in flight. Ties break on the container id, so the order stays
+// deterministic.
+func (g *rtStartGate) Enter(containerID string, util float64) {
+	g.Lock()
+	g.pending[containerID] = util
+	g.Unlock()
+
+	// let the starts racing with this one register before picking an order
+	time.Sleep(g.settle)
+
+	g.Lock()
+	for g.running || !g.firstPending(containerID) {
+		g.cond.Wait()
+	}
+	g.running = true
+	g.Unlock()
+}
+
+// Leave releases the gate after the start of containerID completed or
+// failed. Every Enter must be paired with a Leave.
+func (g *rtStartGate) Leave(containerID string) {
+	g.Lock()
+	delete(g.pending, containerID)
+	g.running = false
+	g.Unlock()
+	g.cond.Broadcast()
+}
+
+// firstPending reports whether containerID holds the largest pending
+// utilization. The caller must hold the lock.
+func (g *rtStartGate) firstPending(containerID string) bool {
+	util := g.pending[containerID]
+	for otherID, otherUtil := range g.pending {
+		if otherID == containerID {
+			continue
+		}
+		if otherUtil > util || (otherUtil == util && otherID < containerID) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,30 +1,95 @@
 package state
 
 import (
+	"fmt"
+	"math"
+	"sort"
+
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 )
 
+// RtAllocationAction is the kind of change carried by an RtAllocationEvent.
+type RtAllocationAction string
+
+const (
+	// RtAllocationAdded means the container got its first RT assignment.
+	RtAllocationAdded RtAllocationAction = "add"
+	// RtAllocationUpdated means an existing RT assignment was replaced.
+	RtAllocationUpdated RtAllocationAction = "update"
+	// RtAllocationRemoved means the RT assignment of the container was
+	// released.
+	RtAllocationRemoved RtAllocationAction = "remove"
+)
+
+// RtAllocationEvent describes a change to the RT allocation of a container,
+// delivered to the subscribed listeners.
+type RtAllocationEvent struct {
+	ContainerID string
+	CPUSet      cpuset.CPUSet
+	Util        float64
+	Action      RtAllocationAction
+}
+
 type RtState struct {
 	State
 	containerToUtil map[string]float64
 	cpuToUtil       map[int]float64
+	// assignableCPUs, when non empty, pins the cpus the RT bookkeeping
+	// covers independently of the default set.
+	assignableCPUs cpuset.CPUSet
+	listeners      []func(RtAllocationEvent)
+}
+
+// Subscribe registers a listener invoked synchronously on every RT allocation
+// change, so metrics exporters and debug tooling don't have to poll the
+// state. Listeners must be fast and must not call back into the state.
+func (s *RtState) Subscribe(listener func(RtAllocationEvent)) {
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *RtState) notify(event RtAllocationEvent) {
+	for _, listener := range s.listeners {
+		listener(event)
+	}
 }
 
 //
 func NewRtState(s State) *RtState {
+	return NewRtStateWithAssignableCPUs(s, cpuset.NewCPUSet())
+}
+
+// NewRtStateWithAssignableCPUs builds an RtState whose per-cpu utilization
+// covers exactly the given assignable cpus. The default set may have been
+// narrowed by an old checkpoint, so the policy passes the RT-eligible cpus of
+// the machine explicitly; with an empty set the bookkeeping follows the
+// default set, as before.
+func NewRtStateWithAssignableCPUs(s State, assignable cpuset.CPUSet) *RtState {
 	rts := &RtState{
 		State:           s,
 		containerToUtil: make(map[string]float64),
+		assignableCPUs:  assignable,
 	}
 
-	rts.cpuToUtil = make(map[int]float64, s.GetDefaultCPUSet().Size())
-	for _, cpu := range s.GetDefaultCPUSet().ToSliceNoSort() {
+	covered := rts.rtAssignableCPUs()
+	rts.cpuToUtil = make(map[int]float64, covered.Size())
+	for _, cpu := range covered.ToSliceNoSort() {
 		rts.cpuToUtil[cpu] = 0
 	}
 
 	return rts
 }
 
+// rtAssignableCPUs returns the cpus the RT bookkeeping covers: the explicit
+// assignable set when one was given at construction, the default set
+// otherwise.
+func (s *RtState) rtAssignableCPUs() cpuset.CPUSet {
+	if s.assignableCPUs.Size() > 0 {
+		return s.assignableCPUs
+	}
+	return s.GetDefaultCPUSet()
+}
+
 //
 func (s RtState) GetRtCPUSetAndUtilOfContainer(containerID string) (cpuset.CPUSet, float64, bool) {
 	cpuSet, ok := s.GetCPUSet(containerID)
@@ -40,6 +105,13 @@ func (s RtState) GetRtCPUSetAndUtilOfContainer(containerID string) (cpuset.CPUSe
 	return cpuSet, util, true
 }
 
+// GetRtCPUSet returns only the cpus assigned to the container, for the
+// callers that have no use for the utilization.
+func (s RtState) GetRtCPUSet(containerID string) (cpuset.CPUSet, bool) {
+	cpuSet, _, ok := s.GetRtCPUSetAndUtilOfContainer(containerID)
+	return cpuSet, ok
+}
+
 //
 func (s *RtState) SetRtCPUSetAndUtilOfContainer(containerID string, set cpuset.CPUSet, util float64) {
 
@@ -61,6 +133,12 @@ func (s *RtState) SetRtCPUSetAndUtilOfContainer(containerID string, set cpuset.C
 	for _, cpu := range set.ToSliceNoSort() {
 		s.cpuToUtil[cpu] += util
 	}
+
+	action := RtAllocationAdded
+	if ok {
+		action = RtAllocationUpdated
+	}
+	s.notify(RtAllocationEvent{ContainerID: containerID, CPUSet: set, Util: util, Action: action})
 }
 
 //
@@ -87,6 +165,264 @@ func (s *RtState) Delete(containerID string) {
 	delete(s.containerToUtil, containerID)
 
 	s.State.Delete(containerID)
+
+	s.notify(RtAllocationEvent{ContainerID: containerID, CPUSet: cpuSet, Util: containerUtil, Action: RtAllocationRemoved})
+}
+
+// Rename moves the RT assignment of oldID under newID, for runtimes that
+// change the container id across certain operations. The cpuset and the
+// utilization carry over untouched, so unlike a release and a fresh
+// allocation the container cannot lose its cpus to fragmentation in between.
+// The per-cpu utilization is unaffected. It fails when oldID holds no RT
+// assignment or newID already holds one.
+func (s *RtState) Rename(oldID, newID string) error {
+	if oldID == newID {
+		return nil
+	}
+
+	set, util, ok := s.GetRtCPUSetAndUtilOfContainer(oldID)
+	if !ok {
+		return fmt.Errorf("container %s holds no RT assignment", oldID)
+	}
+	if _, ok := s.containerToUtil[newID]; ok {
+		return fmt.Errorf("container %s already holds an RT assignment", newID)
+	}
+
+	s.SetCPUSet(newID, set)
+	s.containerToUtil[newID] = util
+	delete(s.containerToUtil, oldID)
+	s.State.Delete(oldID)
+
+	s.notify(RtAllocationEvent{ContainerID: oldID, CPUSet: set, Util: util, Action: RtAllocationRemoved})
+	s.notify(RtAllocationEvent{ContainerID: newID, CPUSet: set, Util: util, Action: RtAllocationAdded})
+
+	return nil
+}
+
+// GetRtContainers returns the ids of the containers currently holding RT
+// utilization, sorted for reproducibility.
+func (s *RtState) GetRtContainers() []string {
+	containerIDs := make([]string, 0, len(s.containerToUtil))
+	for containerID := range s.containerToUtil {
+		containerIDs = append(containerIDs, containerID)
+	}
+	sort.Strings(containerIDs)
+	return containerIDs
+}
+
+// MaxFittingUtilization returns the largest per-container RT utilization
+// that could still be placed on at least one cpu under the given per-cpu
+// allocatable utilization, the headroom of the freest cpu. It returns 0 when
+// the state tracks no cpus or every cpu is at or over the cap.
+func (s RtState) MaxFittingUtilization(allocableRtUtil float64) float64 {
+	maxFitting := 0.0
+	for _, util := range s.cpuToUtil {
+		if headroom := allocableRtUtil - util; headroom > maxFitting {
+			maxFitting = headroom
+		}
+	}
+	return maxFitting
+}
+
+// CanAccommodate reports whether every cpu in set can take util additional
+// RT utilization without crossing the given per-cpu allocatable cap. Cpus
+// the state does not track offer no RT headroom at all. The fit functions
+// should never produce a set this rejects, so it guards the commit of an
+// assignment against their bugs.
+func (s RtState) CanAccommodate(set cpuset.CPUSet, util float64, allocableRtUtil float64) bool {
+	for _, cpu := range set.ToSliceNoSort() {
+		current, ok := s.cpuToUtil[cpu]
+		if !ok || current+util > allocableRtUtil+1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+// UtilizationVariance returns the variance of the RT utilization across the
+// tracked cpus, a measure of how unbalanced the placement is: 0 means every
+// cpu holds the same utilization, higher values mean the load concentrates on
+// a few cpus and the node is a candidate for rebalancing. It returns 0 when
+// the state tracks no cpus.
+func (s RtState) UtilizationVariance() float64 {
+	n := float64(len(s.cpuToUtil))
+	if n == 0 {
+		return 0
+	}
+
+	mean := float64(0)
+	for _, util := range s.cpuToUtil {
+		mean += util / n
+	}
+
+	variance := float64(0)
+	for _, util := range s.cpuToUtil {
+		variance += ((util - mean) * (util - mean)) / n
+	}
+	return variance
+}
+
+// OverCommittedCPUs returns, sorted, the cpus whose tracked RT utilization
+// exceeds the given cap. Under a correct policy no cpu should ever cross the
+// cap, so a non-empty result points at a bug or at drift between the state
+// and the actual assignments, and is worth alerting on.
+func (s RtState) OverCommittedCPUs(cap float64) []int {
+	var overCommitted []int
+	for cpu, util := range s.cpuToUtil {
+		if util > cap {
+			overCommitted = append(overCommitted, cpu)
+		}
+	}
+	sort.Ints(overCommitted)
+	return overCommitted
+}
+
+// LoadAssignments replaces the RT assignments with the given cpusets and
+// utilizations, recomputing the per-cpu utilization. The input is validated
+// before anything is applied: every container needs both a cpuset and a
+// utilization, only cpus of the default set may be used and no cpu may end up
+// over full RT utilization. On error the state is left untouched.
+func (s *RtState) LoadAssignments(cpusets map[string]cpuset.CPUSet, utils map[string]float64) error {
+	for containerID := range cpusets {
+		if _, ok := utils[containerID]; !ok {
+			return fmt.Errorf("container %s has a cpuset but no utilization", containerID)
+		}
+	}
+
+	assignable := s.rtAssignableCPUs()
+	cpuToUtil := make(map[int]float64, assignable.Size())
+	for _, cpu := range assignable.ToSliceNoSort() {
+		cpuToUtil[cpu] = 0
+	}
+	for containerID, util := range utils {
+		set, ok := cpusets[containerID]
+		if !ok {
+			return fmt.Errorf("container %s has a utilization but no cpuset", containerID)
+		}
+		if util < 0 || util > 1 {
+			return fmt.Errorf("container %s utilization %f outside [0, 1]", containerID, util)
+		}
+		for _, cpu := range set.ToSliceNoSort() {
+			if _, ok := cpuToUtil[cpu]; !ok {
+				return fmt.Errorf("container %s is assigned to cpu %d outside the assignable set", containerID, cpu)
+			}
+			cpuToUtil[cpu] += util
+			if cpuToUtil[cpu] > 1 {
+				return fmt.Errorf("cpu %d ends up over full RT utilization: %f", cpu, cpuToUtil[cpu])
+			}
+		}
+	}
+
+	// the input is consistent, commit it
+	for containerID := range s.containerToUtil {
+		s.State.Delete(containerID)
+	}
+	s.containerToUtil = make(map[string]float64, len(utils))
+	for containerID, util := range utils {
+		s.SetCPUSet(containerID, cpusets[containerID])
+		s.containerToUtil[containerID] = util
+	}
+	s.cpuToUtil = cpuToUtil
+
+	return nil
+}
+
+// CleanOrphanedUtil removes containerToUtil entries whose container has no
+// cpuset left in the underlying state, the residue of a partial
+// SetRtCPUSetAndUtilOfContainer or of an out of band delete, and rebuilds
+// cpuToUtil from the remaining assignments. It returns the ids of the
+// removed entries, sorted.
+func (s *RtState) CleanOrphanedUtil() []string {
+	var orphaned []string
+	for containerID := range s.containerToUtil {
+		if _, ok := s.GetCPUSet(containerID); !ok {
+			orphaned = append(orphaned, containerID)
+		}
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	for _, containerID := range orphaned {
+		delete(s.containerToUtil, containerID)
+	}
+
+	for cpu := range s.cpuToUtil {
+		s.cpuToUtil[cpu] = 0
+	}
+	for containerID, util := range s.containerToUtil {
+		set, ok := s.GetCPUSet(containerID)
+		if !ok {
+			continue
+		}
+		for _, cpu := range set.ToSliceNoSort() {
+			s.cpuToUtil[cpu] += util
+		}
+	}
+
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+// VerifyRelease checks that containerID no longer holds any RT utilization:
+// it must be absent from the container to utilization map and from the cpu
+// assignments, and the per-cpu utilization must match what the remaining
+// containers hold. It returns an error describing the residue, if any.
+func (s *RtState) VerifyRelease(containerID string) error {
+	if util, ok := s.containerToUtil[containerID]; ok {
+		return fmt.Errorf("container %s still holds utilization %f", containerID, util)
+	}
+	if set, ok := s.GetCPUSet(containerID); ok {
+		return fmt.Errorf("container %s is still assigned to cpus %s", containerID, set)
+	}
+
+	tracked := float64(0)
+	for _, util := range s.cpuToUtil {
+		tracked += util
+	}
+	expected := float64(0)
+	for id, util := range s.containerToUtil {
+		if set, ok := s.GetCPUSet(id); ok {
+			expected += util * float64(set.Size())
+		}
+	}
+	if math.Abs(tracked-expected) > 1e-9 {
+		return fmt.Errorf("per-cpu utilization residue: tracking %f, remaining containers hold %f", tracked, expected)
+	}
+
+	return nil
+}
+
+// AggregateRtRuntimes returns, for every cpu in set and in its sorted order,
+// the total RT runtime in microseconds reserved on that cpu by all the
+// containers assigned to it, scaled to the given period. Cpus shared by more
+// than one container carry the sum of their reservations.
+//
+// The runtime is rounded down once from the aggregate utilization instead of
+// summing per-container rounded values: rounding each container up would
+// accumulate up to a microsecond of error per container sharing the cpu, so
+// many small reservations could push the written runtime past the bandwidth
+// the bookkeeping admitted.
+func (s *RtState) AggregateRtRuntimes(set cpuset.CPUSet, period int64) []int64 {
+	runtimes := make([]int64, 0, set.Size())
+	for _, cpu := range set.ToSlice() {
+		runtimes = append(runtimes, int64(math.Floor(s.cpuToUtil[cpu]*float64(period))))
+	}
+	return runtimes
+}
+
+// NodeCPUUtil returns the tracked RT utilization of the cpus on the given
+// NUMA node, keyed by cpu id, for socket-aware placement and diagnostics.
+// Cpus of the node the bookkeeping does not cover are left out.
+func (s *RtState) NodeCPUUtil(topo *topology.CPUTopology, nodeID int) map[int]float64 {
+	inNode := topo.CPUDetails.CPUsInNUMANodes(nodeID)
+	nodeCpuToUtil := make(map[int]float64)
+	for cpu, util := range s.cpuToUtil {
+		if inNode.Contains(cpu) {
+			nodeCpuToUtil[cpu] = util
+		}
+	}
+	return nodeCpuToUtil
 }
 
 //
@@ -102,8 +438,9 @@ func (s *RtState) CpuToUtilMap() map[int]float64 {
 func (s *RtState) SetDefaultCPUSet(set cpuset.CPUSet) {
 	s.State.SetDefaultCPUSet(set)
 
-	s.cpuToUtil = make(map[int]float64, set.Size())
-	for _, cpu := range set.ToSliceNoSort() {
+	covered := s.rtAssignableCPUs()
+	s.cpuToUtil = make(map[int]float64, covered.Size())
+	for _, cpu := range covered.ToSliceNoSort() {
 		s.cpuToUtil[cpu] = 0
 	}
 }
@@ -18,6 +18,7 @@ package cm
 
 import (
 	"k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 )
 
 func NewFakeInternalContainerLifecycle() *fakeInternalContainerLifecycle {
@@ -26,14 +27,26 @@ func NewFakeInternalContainerLifecycle() *fakeInternalContainerLifecycle {
 
 type fakeInternalContainerLifecycle struct{}
 
-func (f *fakeInternalContainerLifecycle) PreStartContainer(pod *v1.Pod, container *v1.Container, containerID string) error {
-	return nil
+func (f *fakeInternalContainerLifecycle) PreStartContainer(pod *v1.Pod, container *v1.Container, containerID string) (PreStartContainerResult, error) {
+	return PreStartContainerResult{}, nil
 }
 
 func (f *fakeInternalContainerLifecycle) PreStopContainer(containerID string) error {
 	return nil
 }
 
+func (f *fakeInternalContainerLifecycle) ReleaseExitedContainer(containerID string) error {
+	return nil
+}
+
 func (f *fakeInternalContainerLifecycle) PostStopContainer(containerID string) error {
 	return nil
 }
+
+func (f *fakeInternalContainerLifecycle) PreCreatePodSandbox(pod *v1.Pod) error {
+	return nil
+}
+
+func (f *fakeInternalContainerLifecycle) GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool) {
+	return cpuset.CPUSet{}, 0, false
+}
@@ -21,6 +21,25 @@ import (
 	evictionapi "k8s.io/kubernetes/pkg/kubelet/eviction/api"
 )
 
+// rtRuntimeAllocatable derives the RT runtime allocatable to pods: the node's
+// RT capacity minus every given reservation holding an rt_runtime entry,
+// floored at zero.
+func rtRuntimeAllocatable(capacityRtRuntime int64, reservations ...v1.ResourceList) int64 {
+	rtRuntime := capacityRtRuntime
+	for _, reservation := range reservations {
+		if reservation == nil {
+			continue
+		}
+		if q, exists := reservation[v1.ResourceRtRuntime]; exists {
+			rtRuntime -= q.Value()
+		}
+	}
+	if rtRuntime < 0 {
+		return 0
+	}
+	return rtRuntime
+}
+
 // hardEvictionReservation returns a resourcelist that includes reservation of resources based on hard eviction thresholds.
 func hardEvictionReservation(thresholds []evictionapi.Threshold, capacity v1.ResourceList) v1.ResourceList {
 	if len(thresholds) == 0 {
@@ -18,24 +18,82 @@ package cm
 
 import (
 	"fmt"
-	"io/ioutil"
 	"k8s.io/api/core/v1"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/klog"
 	kubefeatures "k8s.io/kubernetes/pkg/features"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager"
 )
 
+const (
+	// RtWriteFailurePolicyAnnotation selects how a failure to apply the RT
+	// cgroup parameters is handled for the pod: "fail-open" starts the
+	// container without RT guarantees, any other value (or no annotation)
+	// fails the container start.
+	RtWriteFailurePolicyAnnotation = "kubernetes.io/rt-write-failure-policy"
+	// RtWriteFailOpen is the annotation value selecting fail-open.
+	RtWriteFailOpen = "fail-open"
+	// RtWriteModeAnnotation selects how the RT cgroup parameters are applied
+	// at container start: "async" applies them in the background with a
+	// bounded retry, trading strict guarantees at start for a faster
+	// container start. Any other value (or no annotation) applies them
+	// synchronously before the container starts.
+	RtWriteModeAnnotation = "kubernetes.io/rt-write-mode"
+	// RtWriteAsync is the annotation value selecting the asynchronous mode.
+	RtWriteAsync = "async"
+
+	// rtAsyncWriteRetries bounds the attempts of an asynchronous RT cgroup
+	// write.
+	rtAsyncWriteRetries = 3
+	// rtAsyncWriteRetryDelay is the pause between asynchronous attempts.
+	rtAsyncWriteRetryDelay = 100 * time.Millisecond
+)
+
+// PreStartContainerResult reports what PreStartContainer set up for the
+// container, so callers can log or record the RT allocation without querying
+// the cpu manager again. RtCPUSet and RtUtil are only meaningful when Rt is
+// true.
+type PreStartContainerResult struct {
+	// Rt is true when the cpu manager holds an RT assignment for the
+	// container.
+	Rt bool
+	// RtCPUSet holds the cpus of the RT assignment.
+	RtCPUSet cpuset.CPUSet
+	// RtUtil holds the per-cpu RT utilization of the assignment.
+	RtUtil float64
+}
+
 type InternalContainerLifecycle interface {
-	PreStartContainer(pod *v1.Pod, container *v1.Container, containerID string) error
+	// PreStartContainer runs the internal hooks before the container starts
+	// and reports the RT allocation it set up, if any.
+	PreStartContainer(pod *v1.Pod, container *v1.Container, containerID string) (PreStartContainerResult, error)
 	PreStopContainer(containerID string) error
 	PostStopContainer(containerID string) error
+	// ReleaseExitedContainer releases the RT utilization still held by a
+	// container observed as exited, so a replacement can be admitted without
+	// waiting for the next reconcile. It is a no-op for containers the CPU
+	// manager does not track as real-time.
+	ReleaseExitedContainer(containerID string) error
+	// PreCreatePodSandbox creates the pod level cgroup of an RT pod with its
+	// RT period applied before the sandbox starts, so the RT runtimes can be
+	// written to it when the containers come up. It is a no-op for non RT
+	// pods.
+	PreCreatePodSandbox(pod *v1.Pod) error
+	// GetRtCPUSetAndUtil returns the cpus and the RT utilization the CPU
+	// manager assigned to the container, false when the container holds no
+	// RT assignment.
+	GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool)
 }
 
 // Implements InternalContainerLifecycle interface.
@@ -45,27 +103,44 @@ type internalContainerLifecycleImpl struct {
 	cm              ContainerManager
 }
 
-func (i *internalContainerLifecycleImpl) PreStartContainer(pod *v1.Pod, container *v1.Container, containerID string) error {
+func (i *internalContainerLifecycleImpl) PreStartContainer(pod *v1.Pod, container *v1.Container, containerID string) (PreStartContainerResult, error) {
 	if utilfeature.DefaultFeatureGate.Enabled(kubefeatures.CPUManager) {
 		err := i.cpuManager.AddContainer(pod, container, containerID)
 		if err != nil {
-			return err
+			return PreStartContainerResult{}, err
 		}
 	}
 	_, ok := i.cpuManager.State().GetCPUSet(containerID)
 	cpuRtRuntime := container.Resources.Requests.CpuRtRuntime()
 	if ok && !cpuRtRuntime.IsZero() {
-		if err := i.ensureCpuRtMultiRuntime(pod, container, containerID); err != nil {
-			return err
+		write := func() error { return i.ensureCpuRtMultiRuntime(pod, container, containerID) }
+		if rtWriteAsync(pod) {
+			go func() {
+				if err := retryRtWrite(write); err != nil {
+					klog.Errorf("[ContainerManager] applying the RT cgroup parameters of container %s in pod %s asynchronously: %v", container.Name, pod.Name, err)
+				}
+			}()
+		} else if err := write(); err != nil {
+			if err := handleRtWriteError(pod, container, err); err != nil {
+				return PreStartContainerResult{}, err
+			}
 		}
 	}
 	if utilfeature.DefaultFeatureGate.Enabled(kubefeatures.TopologyManager) {
 		err := i.topologyManager.AddContainer(pod, containerID)
 		if err != nil {
-			return err
+			return PreStartContainerResult{}, err
 		}
 	}
-	return nil
+	result := PreStartContainerResult{}
+	if cpus, util, ok := i.cpuManager.GetRtCPUSetAndUtil(string(pod.UID), container.Name); ok {
+		result = PreStartContainerResult{Rt: true, RtCPUSet: cpus, RtUtil: util}
+	}
+	return result, nil
+}
+
+func (i *internalContainerLifecycleImpl) GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool) {
+	return i.cpuManager.GetRtCPUSetAndUtil(podUID, containerName)
 }
 
 func (i *internalContainerLifecycleImpl) PreStopContainer(containerID string) error {
@@ -91,6 +166,145 @@ func (i *internalContainerLifecycleImpl) PostStopContainer(containerID string) e
 	return nil
 }
 
+// podRtPeriodConflict returns the RT period requested by a sibling container
+// of the pod when it differs from period. The pod cgroup carries a single RT
+// period, so containers disagreeing on it cannot be enforced together.
+func podRtPeriodConflict(pod *v1.Pod, container *v1.Container, period int64) (int64, bool) {
+	allContainers := []v1.Container{}
+	allContainers = append(allContainers, pod.Spec.InitContainers...)
+	allContainers = append(allContainers, pod.Spec.Containers...)
+	for idx := range allContainers {
+		sibling := &allContainers[idx]
+		if sibling.Name == container.Name {
+			continue
+		}
+		siblingPeriod := sibling.Resources.Requests.CpuRtPeriod()
+		if siblingPeriod.IsZero() {
+			continue
+		}
+		if siblingPeriod.Value() != period {
+			return siblingPeriod.Value(), true
+		}
+	}
+	return 0, false
+}
+
+// rtWriteAsync reports whether the pod selected the asynchronous RT write
+// mode.
+func rtWriteAsync(pod *v1.Pod) bool {
+	return pod.Annotations[RtWriteModeAnnotation] == RtWriteAsync
+}
+
+// retryRtWrite retries write up to rtAsyncWriteRetries times, pausing
+// rtAsyncWriteRetryDelay between the attempts, and returns the last error
+// when every attempt failed.
+func retryRtWrite(write func() error) error {
+	var err error
+	for attempt := 0; attempt < rtAsyncWriteRetries; attempt++ {
+		if err = write(); err == nil {
+			return nil
+		}
+		time.Sleep(rtAsyncWriteRetryDelay)
+	}
+	return err
+}
+
+// handleRtWriteError decides whether a failed RT cgroup write aborts the
+// container start. Pods annotated with fail-open come up without their RT
+// guarantees, everything else fails closed.
+func handleRtWriteError(pod *v1.Pod, container *v1.Container, err error) error {
+	if pod.Annotations[RtWriteFailurePolicyAnnotation] == RtWriteFailOpen {
+		klog.Warningf("[ContainerManager] starting container %s of pod %s without real-time guarantees: %v", container.Name, pod.Name, err)
+		return nil
+	}
+	return err
+}
+
+func (i *internalContainerLifecycleImpl) ReleaseExitedContainer(containerID string) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(kubefeatures.CPUManager) {
+		return nil
+	}
+	for _, id := range i.cpuManager.GetRtContainers() {
+		if id == containerID {
+			return i.cpuManager.RemoveContainer(containerID)
+		}
+	}
+	return nil
+}
+
+func (i *internalContainerLifecycleImpl) PreCreatePodSandbox(pod *v1.Pod) error {
+	period := int64(0)
+	allContainers := []v1.Container{}
+	allContainers = append(allContainers, pod.Spec.InitContainers...)
+	allContainers = append(allContainers, pod.Spec.Containers...)
+	for idx := range allContainers {
+		if allContainers[idx].Resources.Requests.CpuRtRuntime().IsZero() {
+			continue
+		}
+		period = allContainers[idx].Resources.Requests.CpuRtPeriod().Value()
+		break
+	}
+	if period == 0 {
+		return nil
+	}
+
+	CpuSubsystemMountPoint, ok := i.cm.GetMountedSubsystems().MountPoints["cpu"]
+	if !ok {
+		return fmt.Errorf("cpu subsystem unmounted")
+	}
+	pcm := i.cm.NewPodContainerManager()
+	_, podCgroupFs := pcm.GetPodContainerName(pod)
+	return prepareRtPodCgroup(filepath.Join(CpuSubsystemMountPoint, podCgroupFs), period)
+}
+
+// prepareRtPodCgroup creates the pod cgroup directory and applies the RT
+// period to it, proving the RT interface files writable before any container
+// relies on them.
+func prepareRtPodCgroup(podCgroupFs string, period int64) error {
+	if err := rtFs.MkdirAll(podCgroupFs, os.ModePerm); err != nil {
+		return fmt.Errorf("creating the pod cgroupFs %s: %v", podCgroupFs, err)
+	}
+	return writeRtFile(filepath.Join(podCgroupFs, "cpu.rt_period_us"), period)
+}
+
+const (
+	// rtWriteRetries bounds the retries of a transiently failing RT cgroup
+	// write.
+	rtWriteRetries = 3
+	// rtWriteRetryDelay is the base delay between the retries; up to the same
+	// amount of jitter is added so concurrent writers do not retry in
+	// lockstep.
+	rtWriteRetryDelay = 10 * time.Millisecond
+)
+
+// isTransientRtWriteError distinguishes the RT cgroup write errors worth
+// retrying from the permanent ones: EBUSY, EAGAIN and EINTR come from the
+// kernel transiently refusing the bandwidth update, while ENOENT means the
+// kernel does not expose the RT interface files at all and no retry can
+// help.
+func isTransientRtWriteError(err error) bool {
+	if os.IsNotExist(err) {
+		return false
+	}
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	return err == syscall.EBUSY || err == syscall.EAGAIN || err == syscall.EINTR
+}
+
+// retryTransientRtWrite runs write, retrying it up to rtWriteRetries times
+// with a jittered delay as long as it keeps failing transiently. Permanent
+// errors are returned immediately.
+func retryTransientRtWrite(write func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := write()
+		if err == nil || attempt == rtWriteRetries || !isTransientRtWriteError(err) {
+			return err
+		}
+		time.Sleep(rtWriteRetryDelay + time.Duration(rand.Int63n(int64(rtWriteRetryDelay))))
+	}
+}
+
 //
 func writeCpuRtMultiRuntimeFile(cgroupFs string, cpuSet cpuset.CPUSet, rtRuntime int64) error {
 	// TODO(stefano.fiori): can we write with opencontainer approach?
@@ -102,7 +316,7 @@ func writeCpuRtMultiRuntimeFile(cgroupFs string, cpuSet cpuset.CPUSet, rtRuntime
 		return nil
 	}
 
-	if err := os.MkdirAll(cgroupFs, os.ModePerm); err != nil {
+	if err := rtFs.MkdirAll(cgroupFs, os.ModePerm); err != nil {
 		return fmt.Errorf("creating the container cgroupFs %s: %v", cgroupFs, err)
 	}
 
@@ -115,22 +329,79 @@ func writeCpuRtMultiRuntimeFile(cgroupFs string, cpuSet cpuset.CPUSet, rtRuntime
 	rtRuntimeStr := strconv.FormatInt(rtRuntime, 10)
 	str := cpuSet.String() + " " + rtRuntimeStr
 
-	if err := ioutil.WriteFile(filePath, []byte(str), os.ModePerm); err != nil {
+	if err := retryTransientRtWrite(func() error {
+		return rtFs.WriteFile(filePath, []byte(str), os.ModePerm)
+	}); err != nil {
 		return fmt.Errorf("writing %s in cpu.rt_multi_runtime_us, path %s: %v", str, filePath, err)
 	}
 	return nil
 }
 
+// writeCpuRtMultiRuntimesFile writes a distinct RT runtime for every cpu in
+// set, so cpus shared by more than one container carry their aggregate
+// runtime instead of the runtime written last.
+func writeCpuRtMultiRuntimesFile(cgroupFs string, set cpuset.CPUSet, rtRuntimes []int64) error {
+	for idx, cpu := range set.ToSlice() {
+		if err := writeCpuRtMultiRuntimeFile(cgroupFs, cpuset.NewCPUSet(cpu), rtRuntimes[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aggregateRtRuntimes computes the per-cpu aggregate RT runtimes of set from
+// the cpu manager's RT bookkeeping. When the state does not carry per-cpu
+// utilization it falls back to the container's own runtime on every cpu.
+func aggregateRtRuntimes(reader state.Reader, set cpuset.CPUSet, period int64, containerRuntime int64) []int64 {
+	if rtState, ok := reader.(*state.RtState); ok {
+		return rtState.AggregateRtRuntimes(set, period)
+	}
+	runtimes := make([]int64, set.Size())
+	for idx := range runtimes {
+		runtimes[idx] = containerRuntime
+	}
+	return runtimes
+}
+
+// podRtRuntimes computes the per-cpu RT runtimes to apply on the pod cgroup:
+// the aggregate of the containers' reservations on each cpu, plus the RT
+// overhead the pod's RuntimeClass declares. The overhead runs in the pod
+// cgroup outside any container, so it is provisioned on every cpu of the set
+// and is covered wherever it lands.
+func podRtRuntimes(reader state.Reader, pod *v1.Pod, set cpuset.CPUSet, period int64, containerRuntime int64) []int64 {
+	runtimes := aggregateRtRuntimes(reader, set, period, containerRuntime)
+	overhead := rtOverheadRuntime(pod)
+	if overhead == 0 {
+		return runtimes
+	}
+	for idx := range runtimes {
+		runtimes[idx] += overhead
+	}
+	return runtimes
+}
+
+// rtOverheadRuntime returns the RT runtime the pod's RuntimeClass overhead
+// adds on top of the containers' reservations, zero when the pod declares no
+// overhead.
+func rtOverheadRuntime(pod *v1.Pod) int64 {
+	if pod.Spec.Overhead == nil || !utilfeature.DefaultFeatureGate.Enabled(kubefeatures.PodOverhead) {
+		return 0
+	}
+	return pod.Spec.Overhead.CpuRtRuntime().Value()
+}
+
 //
 func writeRtFile(cgroupFs string, value int64) error {
 
-	if err := os.MkdirAll(filepath.Dir(cgroupFs), os.ModePerm); err != nil {
+	if err := rtFs.MkdirAll(filepath.Dir(cgroupFs), os.ModePerm); err != nil {
 		return fmt.Errorf("creating the container cgroupFs %s: %v", cgroupFs, err)
 	}
 
 	str := strconv.FormatInt(value, 10)
 
-	if err := ioutil.WriteFile(cgroupFs, []byte(str), os.ModePerm); err != nil {
+	if err := retryTransientRtWrite(func() error {
+		return rtFs.WriteFile(cgroupFs, []byte(str), os.ModePerm)
+	}); err != nil {
 		return fmt.Errorf("writing %s in cpu.rt_multi_runtime_us, path %s: %v", str, value, err)
 	}
 	return nil
@@ -143,7 +414,7 @@ func readCpuRtMultiRuntimeFile(cgroupFs string) ([]int64, error) {
 	)
 
 	filePath := filepath.Join(cgroupFs, CpuRtMultiRuntimeFile)
-	buf, err := ioutil.ReadFile(filePath)
+	buf, err := rtFs.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -176,12 +447,26 @@ func (i *internalContainerLifecycleImpl) ensureCpuRtMultiRuntime(pod *v1.Pod, co
 	pcm := i.cm.NewPodContainerManager()
 	_, podCgroupFs := pcm.GetPodContainerName(pod)
 	podCgroupFs = filepath.Join(CpuSubsystemMountPoint, podCgroupFs)
+	// the pod cgroup carries a single RT period, all the RT containers of
+	// the pod must agree on it
+	if siblingPeriod, conflict := podRtPeriodConflict(pod, container, cpuRtPeriod.Value()); conflict {
+		return fmt.Errorf("container %s of pod %s requests RT period %d but a sibling container uses %d", container.Name, pod.Name, cpuRtPeriod.Value(), siblingPeriod)
+	}
+	// don't silently overwrite a period a sibling container already applied;
+	// the kernel default is exempted since fresh pod cgroups start with it
+	const defaultRtPeriodUs = 1000000
+	if current, err := readRtFile(filepath.Join(podCgroupFs, "cpu.rt_period_us")); err == nil &&
+		current != 0 && current != defaultRtPeriodUs && current != cpuRtPeriod.Value() {
+		return fmt.Errorf("pod cgroup %s already has RT period %d, container %s requests %d", podCgroupFs, current, container.Name, cpuRtPeriod.Value())
+	}
 	// pod period
 	if err := writeRtFile(filepath.Join(podCgroupFs, "cpu.rt_period_us"), cpuRtPeriod.Value()); err != nil {
 		return err
 	}
-	// pod runtime
-	if err := writeCpuRtMultiRuntimeFile(podCgroupFs, cpuSet, cpuRtRuntime.Value()); err != nil {
+	// pod runtime: cpus shared with sibling RT containers must carry the
+	// aggregate of their reservations, not the runtime written last
+	podRuntimes := podRtRuntimes(i.cpuManager.State(), pod, cpuSet, cpuRtPeriod.Value(), cpuRtRuntime.Value())
+	if err := writeCpuRtMultiRuntimesFile(podCgroupFs, cpuSet, podRuntimes); err != nil {
 		return err
 	}
 	// container Cgroup
@@ -1,36 +1,453 @@
 package cm
 
 import (
-	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
 	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+	kubefeatures "k8s.io/kubernetes/pkg/features"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 )
 
+// stubCpuManager overrides the accessors PreStartContainer relies on, the
+// remaining methods of the embedded interface are never reached.
+type stubCpuManager struct {
+	cpumanager.Manager
+	cpus cpuset.CPUSet
+	util float64
+	ok   bool
+}
+
+func (m *stubCpuManager) AddContainer(pod *v1.Pod, container *v1.Container, containerID string) error {
+	return nil
+}
+
+func (m *stubCpuManager) State() state.Reader {
+	return state.NewMemoryState()
+}
+
+func (m *stubCpuManager) GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool) {
+	return m.cpus, m.util, m.ok
+}
+
+func Test_internalContainerLifecycle_PreStartContainerResult(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "rt-pod-uid"}}
+	container := &v1.Container{Name: "rt-container"}
+
+	// the result carries the RT allocation of the container
+	i := &internalContainerLifecycleImpl{
+		cpuManager: &stubCpuManager{cpus: cpuset.NewCPUSet(1, 2), util: 0.25, ok: true},
+	}
+	result, err := i.PreStartContainer(pod, container, "container-id")
+	if err != nil {
+		t.Fatalf("PreStartContainer() error = %v", err)
+	}
+	if !result.Rt || !result.RtCPUSet.Equals(cpuset.NewCPUSet(1, 2)) || result.RtUtil != 0.25 {
+		t.Errorf("PreStartContainer() result = %+v, want the RT allocation on cpus 1-2 with utilization 0.25", result)
+	}
+
+	// containers without an RT assignment report a zero result
+	i = &internalContainerLifecycleImpl{cpuManager: &stubCpuManager{}}
+	result, err = i.PreStartContainer(pod, container, "container-id")
+	if err != nil {
+		t.Fatalf("PreStartContainer() error = %v", err)
+	}
+	if result.Rt {
+		t.Errorf("PreStartContainer() result = %+v, want no RT allocation", result)
+	}
+}
+
+// fakeRtCgroupFs records the RT cgroup operations in memory, so the writer
+// tests assert exact file contents without touching /sys/fs/cgroup.
+type fakeRtCgroupFs struct {
+	dirs  map[string]bool
+	files map[string]string
+}
+
+func newFakeRtCgroupFs() *fakeRtCgroupFs {
+	return &fakeRtCgroupFs{dirs: make(map[string]bool), files: make(map[string]string)}
+}
+
+func (f *fakeRtCgroupFs) MkdirAll(path string, perm os.FileMode) error {
+	f.dirs[path] = true
+	return nil
+}
+
+func (f *fakeRtCgroupFs) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	f.files[filename] = string(data)
+	return nil
+}
+
+func (f *fakeRtCgroupFs) ReadFile(filename string) ([]byte, error) {
+	content, ok := f.files[filename]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+// swapRtFs installs the given filesystem for the RT cgroup writers and
+// restores the real one when the test ends.
+func swapRtFs(t *testing.T, fs rtCgroupFs) {
+	orig := rtFs
+	rtFs = fs
+	t.Cleanup(func() { rtFs = orig })
+}
+
 func Test_writeCpuRtMultiRuntimeFile(t *testing.T) {
-	type args struct {
-		cgroupFs  string
-		cpuSet    cpuset.CPUSet
-		rtRuntime int64
+	cgroupFs := "/sys/fs/cgroup/cpu,cpuacct/kubepods/burstable/podb2aab547-2e0d-413a-b0c6-81183b6cdb8c"
+
+	// the runtime is written for the whole cpuset in a single line
+	fs := newFakeRtCgroupFs()
+	swapRtFs(t, fs)
+	if err := writeCpuRtMultiRuntimeFile(cgroupFs, cpuset.NewCPUSet(1, 2, 3), 10000000); err != nil {
+		t.Fatalf("writeCpuRtMultiRuntimeFile() error = %v", err)
+	}
+	if !fs.dirs[cgroupFs] {
+		t.Errorf("writeCpuRtMultiRuntimeFile() did not create the cgroup directory %s", cgroupFs)
 	}
+	filePath := filepath.Join(cgroupFs, "cpu.rt_multi_runtime_us")
+	if got, want := fs.files[filePath], "1-3 10000000"; got != want {
+		t.Errorf("cpu.rt_multi_runtime_us = %q, want %q", got, want)
+	}
+
+	// a zero runtime is bumped to the smallest value the kernel accepts
+	fs = newFakeRtCgroupFs()
+	swapRtFs(t, fs)
+	if err := writeCpuRtMultiRuntimeFile(cgroupFs, cpuset.NewCPUSet(1), 0); err != nil {
+		t.Fatalf("writeCpuRtMultiRuntimeFile() error = %v", err)
+	}
+	if got, want := fs.files[filePath], "1 2"; got != want {
+		t.Errorf("cpu.rt_multi_runtime_us = %q, want %q", got, want)
+	}
+
+	// an empty cpuset writes nothing
+	fs = newFakeRtCgroupFs()
+	swapRtFs(t, fs)
+	if err := writeCpuRtMultiRuntimeFile(cgroupFs, cpuset.NewCPUSet(), 10000000); err != nil {
+		t.Fatalf("writeCpuRtMultiRuntimeFile() error = %v", err)
+	}
+	if len(fs.files) != 0 {
+		t.Errorf("writeCpuRtMultiRuntimeFile() wrote %v for an empty cpuset", fs.files)
+	}
+}
+
+func Test_writeRtFile(t *testing.T) {
+	fs := newFakeRtCgroupFs()
+	swapRtFs(t, fs)
+
+	filePath := "/sys/fs/cgroup/cpu,cpuacct/kubepods/burstable/podb2aab547-2e0d-413a-b0c6-81183b6cdb8c/cpu.rt_period_us"
+	if err := writeRtFile(filePath, 1000000); err != nil {
+		t.Fatalf("writeRtFile() error = %v", err)
+	}
+	if !fs.dirs[filepath.Dir(filePath)] {
+		t.Errorf("writeRtFile() did not create the parent directory of %s", filePath)
+	}
+	if got, want := fs.files[filePath], "1000000"; got != want {
+		t.Errorf("cpu.rt_period_us = %q, want %q", got, want)
+	}
+}
+
+// flakyRtCgroupFs fails the first failures writes with the given error, then
+// delegates to the embedded fake.
+type flakyRtCgroupFs struct {
+	*fakeRtCgroupFs
+	failures int
+	err      error
+	attempts int
+}
+
+func (f *flakyRtCgroupFs) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return f.err
+	}
+	return f.fakeRtCgroupFs.WriteFile(filename, data, perm)
+}
+
+func Test_writeRtFileRetriesTransientErrors(t *testing.T) {
+	filePath := "/sys/fs/cgroup/cpu,cpuacct/kubepods/burstable/podb2aab547-2e0d-413a-b0c6-81183b6cdb8c/cpu.rt_period_us"
+
+	// two EBUSY failures stay within the retry budget
+	fs := &flakyRtCgroupFs{
+		fakeRtCgroupFs: newFakeRtCgroupFs(),
+		failures:       2,
+		err:            &os.PathError{Op: "write", Path: filePath, Err: syscall.EBUSY},
+	}
+	swapRtFs(t, fs)
+	if err := writeRtFile(filePath, 1000000); err != nil {
+		t.Fatalf("writeRtFile() error = %v, want the third attempt to succeed", err)
+	}
+	if fs.attempts != 3 {
+		t.Errorf("writeRtFile() took %d attempts, want 3", fs.attempts)
+	}
+	if got, want := fs.files[filePath], "1000000"; got != want {
+		t.Errorf("cpu.rt_period_us = %q, want %q", got, want)
+	}
+
+	// ENOENT means the kernel does not expose the RT files: no retry can help
+	fs = &flakyRtCgroupFs{
+		fakeRtCgroupFs: newFakeRtCgroupFs(),
+		failures:       2,
+		err:            &os.PathError{Op: "write", Path: filePath, Err: syscall.ENOENT},
+	}
+	swapRtFs(t, fs)
+	if err := writeRtFile(filePath, 1000000); err == nil {
+		t.Fatal("writeRtFile() = nil error on an unsupported kernel, want error")
+	}
+	if fs.attempts != 1 {
+		t.Errorf("writeRtFile() took %d attempts on a permanent error, want 1", fs.attempts)
+	}
+
+	// a persistent transient error exhausts the budget and surfaces
+	fs = &flakyRtCgroupFs{
+		fakeRtCgroupFs: newFakeRtCgroupFs(),
+		failures:       rtWriteRetries + 1,
+		err:            &os.PathError{Op: "write", Path: filePath, Err: syscall.EBUSY},
+	}
+	swapRtFs(t, fs)
+	if err := writeRtFile(filePath, 1000000); err == nil {
+		t.Fatal("writeRtFile() = nil error after exhausting the retries, want error")
+	}
+	if fs.attempts != rtWriteRetries+1 {
+		t.Errorf("writeRtFile() took %d attempts, want %d", fs.attempts, rtWriteRetries+1)
+	}
+}
+
+func Test_handleRtWriteError(t *testing.T) {
+	writeErr := errors.New("write rt_multi_runtime_us: read-only file system")
+	container := &v1.Container{Name: "rt-container"}
+
 	tests := []struct {
 		name    string
-		args    args
+		pod     *v1.Pod
 		wantErr bool
 	}{
 		{
-			name: "nominal",
-			args: args{
-				cgroupFs:  "/sys/fs/cgroup/cpu,cpuacct/kubepods/burstable/podb2aab547-2e0d-413a-b0c6-81183b6cdb8c",
-				cpuSet:    cpuset.NewCPUSet(1, 2, 3),
-				rtRuntime: 10000000,
+			name:    "no annotation fails closed",
+			pod:     &v1.Pod{},
+			wantErr: true,
+		},
+		{
+			name: "fail-open annotation starts without guarantees",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						RtWriteFailurePolicyAnnotation: RtWriteFailOpen,
+					},
+				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "unknown annotation value fails closed",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						RtWriteFailurePolicyAnnotation: "fail-closed",
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := writeCpuRtMultiRuntimeFile(tt.args.cgroupFs, tt.args.cpuSet, tt.args.rtRuntime); (err != nil) != tt.wantErr {
-				t.Errorf("writeCpuRtMultiRuntimeFile() error = %v, wantErr %v", err, tt.wantErr)
+			err := handleRtWriteError(tt.pod, container, writeErr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handleRtWriteError() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && err != writeErr {
+				t.Errorf("handleRtWriteError() error = %v, want the original write error", err)
 			}
 		})
 	}
 }
+
+func Test_rtWriteAsync(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want bool
+	}{
+		{
+			name: "no annotation stays synchronous",
+			pod:  &v1.Pod{},
+			want: false,
+		},
+		{
+			name: "async annotation selects the asynchronous mode",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						RtWriteModeAnnotation: RtWriteAsync,
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "unknown annotation value stays synchronous",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						RtWriteModeAnnotation: "eventually",
+					},
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rtWriteAsync(tt.pod); got != tt.want {
+				t.Errorf("rtWriteAsync() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_retryRtWrite(t *testing.T) {
+	// a write failing on the first attempts is eventually applied
+	calls := 0
+	err := retryRtWrite(func() error {
+		calls++
+		if calls < rtAsyncWriteRetries {
+			return errors.New("device or resource busy")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("retryRtWrite() error = %v, want the write eventually applied", err)
+	}
+	if calls != rtAsyncWriteRetries {
+		t.Errorf("retryRtWrite() attempted %d writes, want %d", calls, rtAsyncWriteRetries)
+	}
+
+	// the retries are bounded and the last error surfaces
+	calls = 0
+	writeErr := errors.New("read-only file system")
+	err = retryRtWrite(func() error {
+		calls++
+		return writeErr
+	})
+	if err != writeErr {
+		t.Errorf("retryRtWrite() error = %v, want the last write error", err)
+	}
+	if calls != rtAsyncWriteRetries {
+		t.Errorf("retryRtWrite() attempted %d writes, want %d", calls, rtAsyncWriteRetries)
+	}
+}
+
+func Test_aggregateRtRuntimes(t *testing.T) {
+	s := state.NewRtState(state.NewMemoryState())
+	s.SetDefaultCPUSet(cpuset.NewCPUSet(0, 1, 2, 3))
+
+	// two containers share cpu 1
+	s.SetRtCPUSetAndUtilOfContainer("container-a", cpuset.NewCPUSet(1, 2), 0.1)
+	s.SetRtCPUSetAndUtilOfContainer("container-b", cpuset.NewCPUSet(1), 0.3)
+
+	got := aggregateRtRuntimes(s, cpuset.NewCPUSet(1, 2), 1000000, 100000)
+	want := []int64{400000, 100000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateRtRuntimes() = %v, want %v", got, want)
+	}
+
+	// states without RT bookkeeping fall back to the container's runtime
+	got = aggregateRtRuntimes(state.NewMemoryState(), cpuset.NewCPUSet(1, 2), 1000000, 100000)
+	want = []int64{100000, 100000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateRtRuntimes() fallback = %v, want %v", got, want)
+	}
+}
+
+func Test_podRtRuntimes(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, kubefeatures.PodOverhead, true)()
+
+	// the RuntimeClass declares 5000us of RT overhead for the pod
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Overhead: v1.ResourceList{
+				v1.ResourceRtRuntime: resource.MustParse("5000"),
+			},
+		},
+	}
+
+	got := podRtRuntimes(state.NewMemoryState(), pod, cpuset.NewCPUSet(1, 2), 1000000, 100000)
+	want := []int64{105000, 105000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("podRtRuntimes() = %v, want %v", got, want)
+	}
+
+	// pods without overhead keep the plain aggregate
+	got = podRtRuntimes(state.NewMemoryState(), &v1.Pod{}, cpuset.NewCPUSet(1, 2), 1000000, 100000)
+	want = []int64{100000, 100000}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("podRtRuntimes() without overhead = %v, want %v", got, want)
+	}
+}
+
+func Test_podRtPeriodConflict(t *testing.T) {
+	rtRequests := func(period int64) v1.ResourceRequirements {
+		return v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(period, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(period / 10, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+			},
+		}
+	}
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "rt-a", Resources: rtRequests(1000000)},
+				{Name: "rt-b", Resources: rtRequests(500000)},
+				{Name: "non-rt"},
+			},
+		},
+	}
+
+	// the two RT containers disagree on the period
+	siblingPeriod, conflict := podRtPeriodConflict(pod, &pod.Spec.Containers[0], 1000000)
+	if !conflict || siblingPeriod != 500000 {
+		t.Errorf("podRtPeriodConflict() = (%d, %v), want (500000, true)", siblingPeriod, conflict)
+	}
+
+	// agreeing periods don't conflict, non RT containers are ignored
+	pod.Spec.Containers[1].Resources = rtRequests(1000000)
+	if _, conflict := podRtPeriodConflict(pod, &pod.Spec.Containers[0], 1000000); conflict {
+		t.Error("podRtPeriodConflict() = true for agreeing periods, want false")
+	}
+}
+
+func Test_prepareRtPodCgroup(t *testing.T) {
+	podCgroupFs := filepath.Join(t.TempDir(), "kubepods", "podb2aab547-2e0d-413a-b0c6-81183b6cdb8c")
+
+	if err := prepareRtPodCgroup(podCgroupFs, 1000000); err != nil {
+		t.Fatalf("prepareRtPodCgroup() error = %v", err)
+	}
+
+	periodFile := filepath.Join(podCgroupFs, "cpu.rt_period_us")
+	buf, err := ioutil.ReadFile(periodFile)
+	if err != nil {
+		t.Fatalf("the pod cgroup misses the RT period file: %v", err)
+	}
+	if string(buf) != "1000000" {
+		t.Errorf("cpu.rt_period_us = %q, want \"1000000\"", string(buf))
+	}
+	// the RT files must stay writable for ensureCpuRtMultiRuntime
+	if err := ioutil.WriteFile(periodFile, []byte("2000000"), os.ModePerm); err != nil {
+		t.Errorf("the RT period file is not writable: %v", err)
+	}
+}
@@ -199,9 +199,16 @@ func (cm *containerManagerImpl) getNodeAllocatableAbsoluteImpl(capacity v1.Resou
 		}
 		result[k] = value
 	}
-	// TODO(stefano.fiori): subtract system and kube reserved rt quantities ??
+	// RT bandwidth declared as rt_runtime in the system and kube reserved
+	// lists is written to the reserved cgroups and is not allocatable to
+	// pods; an rt_runtime entry in the hard eviction reservation holds back
+	// bandwidth the same way.
+	rtRuntime := rtRuntimeAllocatable(cm.CpuRtRuntime.Microseconds(),
+		cm.NodeConfig.SystemReserved,
+		cm.NodeConfig.KubeReserved,
+		hardEvictionReservation(cm.HardEvictionThresholds, capacity))
 	result[v1.ResourceRtPeriod] = *resource.NewQuantity(cm.CpuRtPeriod.Microseconds(), resource.DecimalSI)
-	result[v1.ResourceRtRuntime] = *resource.NewQuantity(cm.CpuRtRuntime.Microseconds(), resource.DecimalSI)
+	result[v1.ResourceRtRuntime] = *resource.NewQuantity(rtRuntime, resource.DecimalSI)
 
 	return result
 }
@@ -20,6 +20,7 @@ package cm
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
@@ -258,6 +259,79 @@ func TestNodeAllocatableForEnforcement(t *testing.T) {
 	}
 }
 
+func TestNodeAllocatableRtReservation(t *testing.T) {
+	cm := &containerManagerImpl{
+		NodeConfig: NodeConfig{
+			CpuRtPeriod:  1000000 * time.Microsecond,
+			CpuRtRuntime: 950000 * time.Microsecond,
+			NodeAllocatableConfig: NodeAllocatableConfig{
+				SystemReserved: v1.ResourceList{
+					v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+				},
+				KubeReserved: v1.ResourceList{
+					v1.ResourceRtRuntime: *resource.NewQuantity(50000, resource.DecimalSI),
+				},
+			},
+		},
+		capacity: getResourceList("10", "10Gi"),
+	}
+
+	allocatable := cm.getNodeAllocatableAbsolute()
+
+	rtRuntime := allocatable[v1.ResourceRtRuntime]
+	assert.Equal(t, int64(800000), rtRuntime.Value(), "reserved RT bandwidth must be subtracted from the allocatable RT runtime")
+
+	rtPeriod := allocatable[v1.ResourceRtPeriod]
+	assert.Equal(t, int64(1000000), rtPeriod.Value())
+}
+
+func TestRtRuntimeAllocatable(t *testing.T) {
+	cases := []struct {
+		name         string
+		capacity     int64
+		reservations []v1.ResourceList
+		expected     int64
+	}{
+		{
+			name:     "no reservations",
+			capacity: 950000,
+			expected: 950000,
+		},
+		{
+			name:     "system, kube and eviction reservations subtracted",
+			capacity: 950000,
+			reservations: []v1.ResourceList{
+				{v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI)},
+				{v1.ResourceRtRuntime: *resource.NewQuantity(50000, resource.DecimalSI)},
+				{v1.ResourceRtRuntime: *resource.NewQuantity(25000, resource.DecimalSI)},
+			},
+			expected: 775000,
+		},
+		{
+			name:     "nil and unrelated reservations are ignored",
+			capacity: 950000,
+			reservations: []v1.ResourceList{
+				nil,
+				{v1.ResourceMemory: resource.MustParse("100Mi")},
+			},
+			expected: 950000,
+		},
+		{
+			name:     "over-reservation floors at zero",
+			capacity: 100000,
+			reservations: []v1.ResourceList{
+				{v1.ResourceRtRuntime: *resource.NewQuantity(950000, resource.DecimalSI)},
+			},
+			expected: 0,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, rtRuntimeAllocatable(tc.capacity, tc.reservations...))
+		})
+	}
+}
+
 func TestNodeAllocatableInputValidation(t *testing.T) {
 	memoryEvictionThreshold := resource.MustParse("100Mi")
 	highMemoryEvictionThreshold := resource.MustParse("2Gi")
@@ -0,0 +1,73 @@
+package cm
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+)
+
+// rtAdmissionReason is the admission failure reason reported for pods whose
+// RT requirements cannot fit the node.
+const rtAdmissionReason = "OutOfCpuRt"
+
+// rtAdmitHandler rejects pods whose RT requirements cannot possibly fit the
+// RT capacity the cpu manager still has available, so they fail admission
+// with a clear reason instead of looping through container-start failures.
+type rtAdmitHandler struct {
+	cpuManager cpumanager.Manager
+}
+
+// NewRtAdmitHandler returns a PodAdmitHandler checking the RT requests of a
+// pod against the cpu manager's remaining RT capacity. A nil cpu manager
+// admits everything.
+func NewRtAdmitHandler(cpuManager cpumanager.Manager) lifecycle.PodAdmitHandler {
+	return &rtAdmitHandler{cpuManager: cpuManager}
+}
+
+func (h *rtAdmitHandler) Admit(attrs *lifecycle.PodAdmitAttributes) lifecycle.PodAdmitResult {
+	requested := float64(0)
+	for idx := range attrs.Pod.Spec.Containers {
+		requests := attrs.Pod.Spec.Containers[idx].Resources.Requests
+		util := requests.CpuRtUtilization()
+		if util == 0 {
+			continue
+		}
+		cpus := requests.CpuRt().Value()
+		if cpus == 0 {
+			cpus = 1
+		}
+		requested += util * float64(cpus)
+	}
+	if requested == 0 || h.cpuManager == nil {
+		return lifecycle.PodAdmitResult{Admit: true}
+	}
+
+	available, capacity, ok := h.cpuManager.GetRtUtilizationHeadroom()
+	if !ok {
+		// the active policy does not manage RT resources, nothing to check
+		return lifecycle.PodAdmitResult{Admit: true}
+	}
+	// A Guaranteed RT pod declares RT limits matching its requests, so its
+	// reservation is firm and must fit the headroom left right now. The
+	// reservation of a Burstable pod is elastic, the first to be reclaimed
+	// under pressure, so it is admitted as long as the node could ever hold
+	// it, within the total allocatable RT capacity. Either way this is a
+	// coarse pre-check: a pod may still fail the per-cpu fit in AddContainer.
+	qos := v1qos.GetPodQOS(attrs.Pod)
+	budget := available
+	if qos != v1.PodQOSGuaranteed {
+		budget = capacity
+	}
+	if requested > budget {
+		return lifecycle.PodAdmitResult{
+			Admit:  false,
+			Reason: rtAdmissionReason,
+			Message: fmt.Sprintf("Node did not have enough RT capacity: %s pod %s requests %.3f RT utilization, %.3f admissible",
+				qos, attrs.Pod.Name, requested, budget),
+		}
+	}
+	return lifecycle.PodAdmitResult{Admit: true}
+}
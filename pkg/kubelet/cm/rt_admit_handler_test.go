@@ -0,0 +1,95 @@
+package cm
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
+	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
+)
+
+// rtHeadroomStubManager overrides the headroom accessor the RT admit handler
+// relies on, the remaining methods of the embedded interface are never
+// reached.
+type rtHeadroomStubManager struct {
+	cpumanager.Manager
+	available float64
+	capacity  float64
+	ok        bool
+}
+
+func (m *rtHeadroomStubManager) GetRtUtilizationHeadroom() (float64, float64, bool) {
+	return m.available, m.capacity, m.ok
+}
+
+func makeRtAdmitPod(name string, rtRuntime, rtPeriod, rtCpus string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name: "rt-container",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceRtRuntime: resource.MustParse(rtRuntime),
+						v1.ResourceRtPeriod:  resource.MustParse(rtPeriod),
+						v1.ResourceRtCpu:     resource.MustParse(rtCpus),
+					},
+				},
+			}},
+		},
+	}
+}
+
+// makeGuaranteedRtAdmitPod declares RT limits matching the requests, which
+// puts the pod in the Guaranteed QoS class.
+func makeGuaranteedRtAdmitPod(name string, rtRuntime, rtPeriod, rtCpus string) *v1.Pod {
+	pod := makeRtAdmitPod(name, rtRuntime, rtPeriod, rtCpus)
+	pod.Spec.Containers[0].Resources.Limits = pod.Spec.Containers[0].Resources.Requests.DeepCopy()
+	return pod
+}
+
+func TestRtAdmitHandler(t *testing.T) {
+	// a Guaranteed pod reserves firmly: 0.5 utilization on 2 cpus against
+	// 0.6 available is rejected even though the total capacity could hold it
+	handler := NewRtAdmitHandler(&rtHeadroomStubManager{available: 0.6, capacity: 3.8, ok: true})
+	result := handler.Admit(&lifecycle.PodAdmitAttributes{Pod: makeGuaranteedRtAdmitPod("guaranteed-too-big", "500000", "1000000", "2")})
+	if result.Admit {
+		t.Error("Admit() admitted a Guaranteed pod exceeding the available RT headroom")
+	}
+	if result.Reason != rtAdmissionReason {
+		t.Errorf("Admit() reason = %q, want %q", result.Reason, rtAdmissionReason)
+	}
+
+	// the same pod fits a node with enough headroom
+	handler = NewRtAdmitHandler(&rtHeadroomStubManager{available: 1.5, capacity: 3.8, ok: true})
+	if result := handler.Admit(&lifecycle.PodAdmitAttributes{Pod: makeGuaranteedRtAdmitPod("guaranteed-fitting", "500000", "1000000", "2")}); !result.Admit {
+		t.Errorf("Admit() rejected a fitting Guaranteed RT pod: %s", result.Message)
+	}
+
+	// a Burstable pod holds an elastic reservation: beyond the current
+	// headroom but within the total capacity it is still admitted
+	handler = NewRtAdmitHandler(&rtHeadroomStubManager{available: 0.6, capacity: 3.8, ok: true})
+	if result := handler.Admit(&lifecycle.PodAdmitAttributes{Pod: makeRtAdmitPod("burstable-elastic", "500000", "1000000", "2")}); !result.Admit {
+		t.Errorf("Admit() rejected a Burstable RT pod within the node capacity: %s", result.Message)
+	}
+
+	// not even an elastic reservation can exceed the total capacity
+	handler = NewRtAdmitHandler(&rtHeadroomStubManager{available: 0.6, capacity: 0.8, ok: true})
+	if result := handler.Admit(&lifecycle.PodAdmitAttributes{Pod: makeRtAdmitPod("burstable-too-big", "500000", "1000000", "2")}); result.Admit {
+		t.Error("Admit() admitted a Burstable pod exceeding the node RT capacity")
+	}
+
+	// non-RT pods pass regardless of the headroom
+	handler = NewRtAdmitHandler(&rtHeadroomStubManager{ok: true})
+	if result := handler.Admit(&lifecycle.PodAdmitAttributes{Pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "non-rt"}}}); !result.Admit {
+		t.Errorf("Admit() rejected a non-RT pod: %s", result.Message)
+	}
+
+	// a policy without RT bookkeeping performs no check
+	handler = NewRtAdmitHandler(&rtHeadroomStubManager{ok: false})
+	if result := handler.Admit(&lifecycle.PodAdmitAttributes{Pod: makeRtAdmitPod("no-rt-policy", "500000", "1000000", "2")}); !result.Admit {
+		t.Errorf("Admit() rejected a pod without an RT policy active: %s", result.Message)
+	}
+}
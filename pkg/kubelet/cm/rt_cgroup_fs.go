@@ -0,0 +1,34 @@
+package cm
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+// rtCgroupFs abstracts the filesystem operations of the RT cgroup writers,
+// so tests can run them against an in-memory fake and assert the exact file
+// contents without touching /sys/fs/cgroup.
+type rtCgroupFs interface {
+	MkdirAll(path string, perm os.FileMode) error
+	WriteFile(filename string, data []byte, perm os.FileMode) error
+	ReadFile(filename string) ([]byte, error)
+}
+
+// osRtCgroupFs passes the operations through to the real filesystem.
+type osRtCgroupFs struct{}
+
+func (osRtCgroupFs) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osRtCgroupFs) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(filename, data, perm)
+}
+
+func (osRtCgroupFs) ReadFile(filename string) ([]byte, error) {
+	return ioutil.ReadFile(filename)
+}
+
+// rtFs is the filesystem the RT cgroup writers run against. Production code
+// never changes it; tests swap in a fake and restore it.
+var rtFs rtCgroupFs = osRtCgroupFs{}
@@ -0,0 +1,82 @@
+package cm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog"
+
+	"k8s.io/kubernetes/pkg/kubelet/metrics"
+)
+
+// ReadAppliedRtUtilization computes the RT utilization the kernel is
+// currently applying by reading cpu.rt_period_us and
+// cpu.rt_multi_runtime_us (falling back to cpu.rt_runtime_us) of every pod
+// cgroup under cpuCgroupRoot. It is the ground truth the in-memory per-cpu
+// bookkeeping of the cpu manager can be compared against.
+func ReadAppliedRtUtilization(cpuCgroupRoot string) (float64, error) {
+	total := float64(0)
+
+	err := filepath.Walk(cpuCgroupRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || !strings.HasPrefix(info.Name(), "pod") {
+			return nil
+		}
+
+		period, err := readRtFile(filepath.Join(path, "cpu.rt_period_us"))
+		if err != nil || period <= 0 {
+			// the pod has no RT reservation
+			return filepath.SkipDir
+		}
+
+		runtimes, err := readCpuRtMultiRuntimeFile(path)
+		if err != nil {
+			// kernels without the multi runtime interface expose a single
+			// runtime for the whole cgroup
+			runtime, err := readRtFile(filepath.Join(path, "cpu.rt_runtime_us"))
+			if err != nil {
+				klog.Errorf("[ContainerManager] unable to read RT runtime of pod cgroup %s: %v", path, err)
+				return filepath.SkipDir
+			}
+			runtimes = []int64{runtime}
+		}
+
+		for _, runtime := range runtimes {
+			total += float64(runtime) / float64(period)
+		}
+
+		// the pod cgroup already aggregates its containers
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// RecordRtUtilizationDrift publishes the difference between the RT
+// utilization the cpu manager tracks and the one currently applied in the
+// cgroups, so a persistent drift can be alerted on.
+func RecordRtUtilizationDrift(cpuCgroupRoot string, trackedUtil float64) error {
+	applied, err := ReadAppliedRtUtilization(cpuCgroupRoot)
+	if err != nil {
+		return err
+	}
+	metrics.CPURtUtilizationDrift.Set(trackedUtil - applied)
+	return nil
+}
+
+//
+func readRtFile(path string) (int64, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(buf)), 10, 64)
+}
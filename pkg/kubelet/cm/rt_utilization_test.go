@@ -0,0 +1,53 @@
+package cm
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestRtFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatalf("creating cgroup dir %s: %v", dir, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm); err != nil {
+		t.Fatalf("writing %s in %s: %v", name, dir, err)
+	}
+}
+
+func TestReadAppliedRtUtilization(t *testing.T) {
+	root, err := ioutil.TempDir("", "rt-cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// a pod with per-cpu runtimes of 100000us and 200000us over a 1000000us
+	// period, the trailing fields mirror the kernel's output format
+	multiPod := filepath.Join(root, "kubepods", "burstable", "pod1111")
+	writeTestRtFile(t, multiPod, "cpu.rt_period_us", "1000000\n")
+	writeTestRtFile(t, multiPod, "cpu.rt_multi_runtime_us", "100000 200000 0 total")
+	// its container cgroup must not be double counted
+	writeTestRtFile(t, filepath.Join(multiPod, "container1"), "cpu.rt_period_us", "1000000\n")
+
+	// a pod on a kernel without the multi runtime interface
+	singlePod := filepath.Join(root, "kubepods", "pod2222")
+	writeTestRtFile(t, singlePod, "cpu.rt_period_us", "1000000\n")
+	writeTestRtFile(t, singlePod, "cpu.rt_runtime_us", "500000\n")
+
+	// a pod without any RT reservation
+	if err := os.MkdirAll(filepath.Join(root, "kubepods", "besteffort", "pod3333"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadAppliedRtUtilization(root)
+	if err != nil {
+		t.Fatalf("ReadAppliedRtUtilization() error = %v", err)
+	}
+	if want := 0.8; math.Abs(got-want) > 1e-9 {
+		t.Errorf("ReadAppliedRtUtilization() = %f, want %f", got, want)
+	}
+}
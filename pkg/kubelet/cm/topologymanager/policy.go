@@ -17,6 +17,7 @@ limitations under the License.
 package topologymanager
 
 import (
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
 )
 
@@ -28,3 +29,31 @@ type Policy interface {
 	// and a Pod Admit Handler Response based on hints and policy type
 	Merge(providersHints []map[string][]TopologyHint) (TopologyHint, lifecycle.PodAdmitResult)
 }
+
+// rtHintsLimiting reports whether the RT cpu resource contributed a limiting
+// hint: its provider either found no admittable NUMA affinity at all or none
+// it prefers. In both cases the RT utilization headroom left on the cpus, not
+// their count or alignment, is what blocks a preferred placement.
+func rtHintsLimiting(providersHints []map[string][]TopologyHint) bool {
+	for _, hints := range providersHints {
+		rtHints, ok := hints[string(v1.ResourceRtCpu)]
+		if !ok || rtHints == nil {
+			// the provider has no RT preference, it cannot be the limit
+			continue
+		}
+		if len(rtHints) == 0 {
+			return true
+		}
+		preferred := false
+		for _, hint := range rtHints {
+			if hint.Preferred {
+				preferred = true
+				break
+			}
+		}
+		if !preferred {
+			return true
+		}
+	}
+	return false
+}
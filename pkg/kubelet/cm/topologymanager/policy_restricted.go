@@ -54,5 +54,8 @@ func (p *restrictedPolicy) canAdmitPodResult(hint *TopologyHint) lifecycle.PodAd
 func (p *restrictedPolicy) Merge(providersHints []map[string][]TopologyHint) (TopologyHint, lifecycle.PodAdmitResult) {
 	hint := mergeProvidersHints(p, p.numaNodes, providersHints)
 	admit := p.canAdmitPodResult(&hint)
+	if !admit.Admit && rtHintsLimiting(providersHints) {
+		admit.Message = admit.Message + "; the RT utilization headroom left on the cpus, not their count, is the limiting factor"
+	}
 	return hint, admit
 }
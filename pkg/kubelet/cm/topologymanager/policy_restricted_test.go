@@ -17,7 +17,10 @@ limitations under the License.
 package topologymanager
 
 import (
+	"strings"
 	"testing"
+
+	v1 "k8s.io/api/core/v1"
 )
 
 func TestPolicyRestrictedCanAdmitPodResult(t *testing.T) {
@@ -58,6 +61,36 @@ func TestPolicyRestrictedCanAdmitPodResult(t *testing.T) {
 	}
 }
 
+func TestRestrictedPolicyMergeRtLimitingMessage(t *testing.T) {
+	numaNodes := []int{0, 1}
+	policy := NewRestrictedPolicy(numaNodes)
+
+	// the RT provider found no admittable affinity, the denial should call
+	// out the RT headroom
+	providersHints := []map[string][]TopologyHint{
+		{string(v1.ResourceRtCpu): {}},
+	}
+	_, admit := policy.Merge(providersHints)
+	if admit.Admit {
+		t.Fatal("Expected the pod to be denied")
+	}
+	if !strings.Contains(admit.Message, "RT utilization headroom") {
+		t.Errorf("Expected the denial message to mention the RT headroom, got %q", admit.Message)
+	}
+
+	// a denial without RT hints keeps the generic message
+	providersHints = []map[string][]TopologyHint{
+		{"cpu": {{NUMANodeAffinity: NewTestBitMask(0), Preferred: false}}},
+	}
+	_, admit = policy.Merge(providersHints)
+	if admit.Admit {
+		t.Fatal("Expected the pod to be denied")
+	}
+	if strings.Contains(admit.Message, "RT utilization headroom") {
+		t.Errorf("Expected the generic denial message, got %q", admit.Message)
+	}
+}
+
 func TestRestrictedPolicyMerge(t *testing.T) {
 	numaNodes := []int{0, 1}
 	policy := NewRestrictedPolicy(numaNodes)
@@ -55,5 +55,8 @@ func (p *singleNumaNodePolicy) canAdmitPodResult(hint *TopologyHint) lifecycle.P
 func (p *singleNumaNodePolicy) Merge(providersHints []map[string][]TopologyHint) (TopologyHint, lifecycle.PodAdmitResult) {
 	hint := mergeProvidersHints(p, p.numaNodes, providersHints)
 	admit := p.canAdmitPodResult(&hint)
+	if !admit.Admit && rtHintsLimiting(providersHints) {
+		admit.Message = admit.Message + "; the RT utilization headroom left on the cpus, not their count, is the limiting factor"
+	}
 	return hint, admit
 }
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var (
@@ -87,3 +88,34 @@ func TestConsistentHashContainer(t *testing.T) {
 		t.Errorf("mismatched hash value with v1.15")
 	}
 }
+
+func TestHashContainerRtResourcesStable(t *testing.T) {
+	makeRtContainer := func(period string) *v1.Container {
+		return &v1.Container{
+			Name:  "rt_container",
+			Image: "foo/image:v1",
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU:       resource.MustParse("1"),
+					v1.ResourceMemory:    resource.MustParse("100Mi"),
+					v1.ResourceRtPeriod:  resource.MustParse(period),
+					v1.ResourceRtRuntime: resource.MustParse("100000"),
+					v1.ResourceRtCpu:     resource.MustParse("2"),
+				},
+			},
+		}
+	}
+
+	// The resource lists are maps: the hash must not depend on their
+	// iteration order.
+	reference := HashContainer(makeRtContainer("1000000"))
+	for i := 0; i < 100; i++ {
+		if hash := HashContainer(makeRtContainer("1000000")); hash != reference {
+			t.Fatalf("hash not stable across runs: got %x, want %x", hash, reference)
+		}
+	}
+
+	if hash := HashContainer(makeRtContainer("2000000")); hash == reference {
+		t.Errorf("changing only the RT period did not change the hash %x", hash)
+	}
+}
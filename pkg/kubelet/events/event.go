@@ -25,6 +25,7 @@ const (
 	KillingContainer        = "Killing"
 	PreemptContainer        = "Preempting"
 	BackOffStartContainer   = "BackOff"
+	BackOffRtAdmission      = "RtAdmissionBackOff"
 	ExceededGracePeriod     = "ExceededGracePeriod"
 
 	// Pod event reason list
@@ -40,6 +40,8 @@ const (
 	SignalAllocatableMemoryAvailable Signal = "allocatableMemory.available"
 	// SignalPIDAvailable is amount of PID available for pod allocation
 	SignalPIDAvailable Signal = "pid.available"
+	// SignalCPURtAvailable is amount of RT cpu utilization still assignable, summed across the RT-eligible cpus, in cpu units.
+	SignalCPURtAvailable Signal = "cpurt.available"
 )
 
 // ThresholdOperator is the operator used to express a Threshold.
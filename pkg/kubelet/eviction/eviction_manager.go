@@ -258,6 +258,7 @@ func (m *managerImpl) synchronize(diskInfoProvider DiskInfoProvider, podFunc Act
 
 	// make observations and get a function to derive pod usage stats relative to those observations.
 	observations, statsFunc := makeSignalObservations(summary)
+	makeRtSignalObservation(observations, m.config.RtUtilProvider)
 	debugLogObservations("observations", observations)
 
 	// determine the set of thresholds met independent of grace period
@@ -427,6 +428,7 @@ func (m *managerImpl) reclaimNodeLevelResources(signalToReclaim evictionapi.Sign
 
 		// make observations and get a function to derive pod usage stats relative to those observations.
 		observations, _ := makeSignalObservations(summary)
+		makeRtSignalObservation(observations, m.config.RtUtilProvider)
 		debugLogObservations("observations after resource reclaim", observations)
 
 		// determine the set of thresholds met independent of grace period
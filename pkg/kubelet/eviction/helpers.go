@@ -25,6 +25,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/api/v1/pod"
 	v1resource "k8s.io/kubernetes/pkg/api/v1/resource"
@@ -54,6 +55,8 @@ const (
 	resourceInodes v1.ResourceName = "inodes"
 	// resourcePids, number. internal to this module, used to account for local pid consumption.
 	resourcePids v1.ResourceName = "pids"
+	// resourceCpuRt, utilization in cpu units. internal to this module, used to account for RT cpu bandwidth consumption.
+	resourceCpuRt v1.ResourceName = "cpurt"
 	// OffendingContainersKey is the key in eviction event annotations for the list of container names which exceeded their requests
 	OffendingContainersKey = "offending_containers"
 	// OffendingContainersUsageKey is the key in eviction event annotations for the list of usage of containers which exceeded their requests
@@ -89,6 +92,7 @@ func init() {
 	signalToResource[evictionapi.SignalNodeFsAvailable] = v1.ResourceEphemeralStorage
 	signalToResource[evictionapi.SignalNodeFsInodesFree] = resourceInodes
 	signalToResource[evictionapi.SignalPIDAvailable] = resourcePids
+	signalToResource[evictionapi.SignalCPURtAvailable] = resourceCpuRt
 }
 
 // validSignal returns true if the signal is supported.
@@ -643,6 +647,42 @@ func rankPIDPressure(pods []*v1.Pod, stats statsFunc) {
 	orderedBy(priority).Sort(pods)
 }
 
+// podRtUtilization returns the RT cpu utilization the pod reserves, in cpu
+// units, summed over its containers.
+func podRtUtilization(p *v1.Pod) float64 {
+	total := float64(0)
+	for i := range p.Spec.Containers {
+		requests := p.Spec.Containers[i].Resources.Requests
+		cpus := requests.CpuRt().Value()
+		if cpus == 0 {
+			cpus = 1
+		}
+		total += requests.CpuRtUtilization() * float64(cpus)
+	}
+	return total
+}
+
+// rtUtilization compares pods by the RT cpu utilization they reserve, the
+// pods holding the most RT bandwidth first, since evicting those restores the
+// most RT headroom.
+func rtUtilization(p1, p2 *v1.Pod) int {
+	util1 := podRtUtilization(p1)
+	util2 := podRtUtilization(p2)
+	if util1 == util2 {
+		return 0
+	}
+	if util1 > util2 {
+		return -1
+	}
+	return 1
+}
+
+// rankCPURtPressure orders the input pods in response to exhausted RT cpu
+// headroom.
+func rankCPURtPressure(pods []*v1.Pod, stats statsFunc) {
+	orderedBy(priority, rtUtilization).Sort(pods)
+}
+
 // rankDiskPressureFunc returns a rankFunc that measures the specified fs stats.
 func rankDiskPressureFunc(fsStatsToMeasure []fsStatsType, diskResource v1.ResourceName) rankFunc {
 	return func(pods []*v1.Pod, stats statsFunc) {
@@ -734,6 +774,26 @@ func makeSignalObservations(summary *statsapi.Summary) (signalObservations, stat
 	return result, statsFunc
 }
 
+// makeRtSignalObservation adds the observation for the RT cpu headroom signal
+// from the given provider, expressed in millicpu of RT utilization. The
+// provider comes from the cpu manager rather than the stats summary, since
+// the RT bookkeeping lives there; a nil provider or a policy without RT
+// accounting leaves the signal unobserved.
+func makeRtSignalObservation(observations signalObservations, provider RtUtilProvider) {
+	if provider == nil {
+		return
+	}
+	available, capacity, ok := provider()
+	if !ok {
+		return
+	}
+	observations[evictionapi.SignalCPURtAvailable] = signalObservation{
+		available: resource.NewMilliQuantity(int64(available*1000), resource.DecimalSI),
+		capacity:  resource.NewMilliQuantity(int64(capacity*1000), resource.DecimalSI),
+		time:      metav1.NewTime(time.Now()),
+	}
+}
+
 func getSysContainer(sysContainers []statsapi.ContainerStats, name string) (*statsapi.ContainerStats, error) {
 	for _, cont := range sysContainers {
 		if cont.Name == name {
@@ -957,6 +1017,7 @@ func buildSignalToRankFunc(withImageFs bool) map[evictionapi.Signal]rankFunc {
 		evictionapi.SignalMemoryAvailable:            rankMemoryPressure,
 		evictionapi.SignalAllocatableMemoryAvailable: rankMemoryPressure,
 		evictionapi.SignalPIDAvailable:               rankPIDPressure,
+		evictionapi.SignalCPURtAvailable:             rankCPURtPressure,
 	}
 	// usage of an imagefs is optional
 	if withImageFs {
@@ -1202,6 +1202,54 @@ func TestMakeSignalObservations(t *testing.T) {
 	}
 }
 
+func TestMakeRtSignalObservation(t *testing.T) {
+	threshold := evictionapi.Threshold{
+		Signal:   evictionapi.SignalCPURtAvailable,
+		Operator: evictionapi.OpLessThan,
+		Value: evictionapi.ThresholdValue{
+			Quantity: quantityMustParse("100m"),
+		},
+	}
+
+	// a saturated node fires the signal
+	observations := signalObservations{}
+	makeRtSignalObservation(observations, func() (float64, float64, bool) {
+		return 0, 3.8, true
+	})
+	observed, found := observations[evictionapi.SignalCPURtAvailable]
+	if !found {
+		t.Fatalf("makeRtSignalObservation() did not observe %v", evictionapi.SignalCPURtAvailable)
+	}
+	if expectedCapacity := quantityMustParse("3800m"); observed.capacity.Cmp(*expectedCapacity) != 0 {
+		t.Errorf("capacity = %v, want %v", observed.capacity, expectedCapacity)
+	}
+	if actual := thresholdsMet([]evictionapi.Threshold{threshold}, observations, false); len(actual) != 1 {
+		t.Errorf("thresholdsMet() = %v, want the RT signal fired at saturation", actual)
+	}
+
+	// with headroom left the signal stays quiet
+	observations = signalObservations{}
+	makeRtSignalObservation(observations, func() (float64, float64, bool) {
+		return 1.5, 3.8, true
+	})
+	if actual := thresholdsMet([]evictionapi.Threshold{threshold}, observations, false); len(actual) != 0 {
+		t.Errorf("thresholdsMet() = %v, want no threshold met", actual)
+	}
+
+	// policies without RT accounting leave the signal unobserved
+	observations = signalObservations{}
+	makeRtSignalObservation(observations, func() (float64, float64, bool) {
+		return 0, 0, false
+	})
+	if _, found := observations[evictionapi.SignalCPURtAvailable]; found {
+		t.Error("makeRtSignalObservation() observed the signal without RT accounting")
+	}
+	makeRtSignalObservation(observations, nil)
+	if len(observations) != 0 {
+		t.Error("makeRtSignalObservation() observed the signal without a provider")
+	}
+}
+
 func TestThresholdsMet(t *testing.T) {
 	hardThreshold := evictionapi.Threshold{
 		Signal:   evictionapi.SignalMemoryAvailable,
@@ -50,8 +50,16 @@ type Config struct {
 	KernelMemcgNotification bool
 	// PodCgroupRoot is the cgroup which contains all pods.
 	PodCgroupRoot string
+	// RtUtilProvider, when set, feeds the cpurt.available signal from the
+	// cpu manager's RT bookkeeping.
+	RtUtilProvider RtUtilProvider
 }
 
+// RtUtilProvider returns the RT cpu utilization still assignable on the node
+// and the total allocatable RT utilization, both in cpu units. ok is false
+// when the cpu manager policy keeps no RT accounting.
+type RtUtilProvider func() (available, capacity float64, ok bool)
+
 // Manager evaluates when an eviction threshold for node stability has been met on the node.
 type Manager interface {
 	// Start starts the control loop to monitor eviction thresholds at specified interval.
@@ -19,6 +19,7 @@ package kuberuntime
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -153,6 +154,62 @@ func isInitContainerFailed(status *kubecontainer.ContainerStatus) bool {
 	return false
 }
 
+// isRtContainer reports whether the container reserves real-time cpu time.
+func isRtContainer(container *v1.Container) bool {
+	return !container.Resources.Requests.CpuRtRuntime().IsZero()
+}
+
+// hasRtContainers reports whether any container of the pod reserves
+// real-time cpu time.
+func hasRtContainers(pod *v1.Pod) bool {
+	for i := range pod.Spec.Containers {
+		if isRtContainer(&pod.Spec.Containers[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// rtMinGracePeriod returns the minimum graceful shutdown window in seconds
+// an RT pod requested through RtMinGracePeriodAnnotation, clamped to
+// maxRtMinGracePeriodSeconds. It returns 0 for non RT pods, for pods without
+// the annotation and for unparsable values.
+func rtMinGracePeriod(pod *v1.Pod) int64 {
+	if pod == nil || !hasRtContainers(pod) {
+		return 0
+	}
+	value, ok := pod.Annotations[RtMinGracePeriodAnnotation]
+	if !ok {
+		return 0
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		klog.Warningf("Ignoring invalid %s annotation of pod %s: %q", RtMinGracePeriodAnnotation, pod.Name, value)
+		return 0
+	}
+	if seconds > maxRtMinGracePeriodSeconds {
+		seconds = maxRtMinGracePeriodSeconds
+	}
+	return seconds
+}
+
+// rtUtilOfContainer returns the RT utilization requested by the container,
+// zero for non RT containers.
+func rtUtilOfContainer(container *v1.Container) float64 {
+	return container.Resources.Requests.CpuRtUtilization()
+}
+
+// sortContainersToStartByRtUtil reorders the indexes of the containers to
+// start so that the RT containers come first, highest utilization first.
+// Starting the biggest RT reservations before the smaller ones reduces the
+// chance that a later container fails to fit because the cpus are already
+// fragmented. Non RT containers keep their spec order after the RT ones.
+func sortContainersToStartByRtUtil(pod *v1.Pod, containersToStart []int) {
+	sort.SliceStable(containersToStart, func(i, j int) bool {
+		return rtUtilOfContainer(&pod.Spec.Containers[containersToStart[i]]) > rtUtilOfContainer(&pod.Spec.Containers[containersToStart[j]])
+	})
+}
+
 // getStableKey generates a key (string) to uniquely identify a
 // (pod, container) tuple. The key should include the content of the
 // container, so that any change to the container generates a new key.
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	runtimetesting "k8s.io/cri-api/pkg/apis/testing"
@@ -346,3 +347,121 @@ func TestNamespacesForPod(t *testing.T) {
 		assert.Equal(t, test.expected, actual)
 	}
 }
+
+func TestSortContainersToStartByRtUtil(t *testing.T) {
+	rtContainer := func(name string, runtime int64) v1.Container {
+		return v1.Container{
+			Name: name,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+					v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+					v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+				},
+			},
+		}
+	}
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				rtContainer("rt-small", 100000),
+				{Name: "plain"},
+				rtContainer("rt-big", 500000),
+			},
+		},
+	}
+
+	containersToStart := []int{0, 1, 2}
+	sortContainersToStartByRtUtil(pod, containersToStart)
+	assert.Equal(t, []int{2, 0, 1}, containersToStart, "expected the highest RT utilization first and non RT containers last")
+
+	// without RT containers the spec order is kept
+	plainPod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "a"}, {Name: "b"}},
+		},
+	}
+	containersToStart = []int{0, 1}
+	sortContainersToStartByRtUtil(plainPod, containersToStart)
+	assert.Equal(t, []int{0, 1}, containersToStart)
+}
+
+func TestRtMinGracePeriod(t *testing.T) {
+	rtPod := func(annotations map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "rt-pod",
+				Annotations: annotations,
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "rt",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+								v1.ResourceRtRuntime: *resource.NewQuantity(100000, resource.DecimalSI),
+								v1.ResourceRtCpu:     *resource.NewQuantity(1, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want int64
+	}{
+		{
+			name: "nil pod",
+			pod:  nil,
+			want: 0,
+		},
+		{
+			name: "no annotation",
+			pod:  rtPod(nil),
+			want: 0,
+		},
+		{
+			name: "annotation within bounds",
+			pod:  rtPod(map[string]string{RtMinGracePeriodAnnotation: "10"}),
+			want: 10,
+		},
+		{
+			name: "annotation above the cap",
+			pod:  rtPod(map[string]string{RtMinGracePeriodAnnotation: "300"}),
+			want: maxRtMinGracePeriodSeconds,
+		},
+		{
+			name: "unparsable annotation",
+			pod:  rtPod(map[string]string{RtMinGracePeriodAnnotation: "soon"}),
+			want: 0,
+		},
+		{
+			name: "negative annotation",
+			pod:  rtPod(map[string]string{RtMinGracePeriodAnnotation: "-5"}),
+			want: 0,
+		},
+		{
+			name: "non RT pod",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "plain-pod",
+					Annotations: map[string]string{RtMinGracePeriodAnnotation: "10"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "plain"}},
+				},
+			},
+			want: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, rtMinGracePeriod(test.pod))
+		})
+	}
+}
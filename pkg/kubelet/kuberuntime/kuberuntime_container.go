@@ -140,13 +140,20 @@ func (m *kubeGenericRuntimeManager) startContainer(podSandboxID string, podSandb
 		m.recordContainerEvent(pod, container, containerID, v1.EventTypeWarning, events.FailedToCreateContainer, "Error: %v", s.Message())
 		return s.Message(), ErrCreateContainer
 	}
-	err = m.internalLifecycle.PreStartContainer(pod, container, containerID)
+	preStartResult, err := m.internalLifecycle.PreStartContainer(pod, container, containerID)
 	if err != nil {
 		s, _ := grpcstatus.FromError(err)
 		m.recordContainerEvent(pod, container, containerID, v1.EventTypeWarning, events.FailedToStartContainer, "Internal PreStartContainer hook failed: %v", s.Message())
 		return s.Message(), ErrPreStartHook
 	}
 	m.recordContainerEvent(pod, container, containerID, v1.EventTypeNormal, events.CreatedContainer, fmt.Sprintf("Created container %s", container.Name))
+	if isRtContainer(container) {
+		if preStartResult.Rt {
+			klog.Infof("Created RT container %q in pod %q with cpuset %q and RT utilization %f", container.Name, format.Pod(pod), preStartResult.RtCPUSet.String(), preStartResult.RtUtil)
+		} else {
+			klog.Infof("Created RT container %q in pod %q without an RT assignment from the cpu manager", container.Name, format.Pod(pod))
+		}
+	}
 
 	if ref != nil {
 		m.containerRefManager.SetRef(kubecontainer.ContainerID{
@@ -604,6 +611,13 @@ func (m *kubeGenericRuntimeManager) killContainer(pod *v1.Pod, containerID kubec
 	if gracePeriodOverride != nil {
 		gracePeriod = *gracePeriodOverride
 		klog.V(3).Infof("Killing container %q, but using %d second grace period override", containerID, gracePeriod)
+		// An RT pod can hold a bounded floor under the override: cutting its
+		// shutdown short may leave shared state inconsistent in
+		// tightly-coupled control loops.
+		if minGrace := rtMinGracePeriod(pod); gracePeriod < minGrace {
+			gracePeriod = minGrace
+			klog.V(3).Infof("Raising the grace period of container %q to the RT minimum %d requested by pod %q", containerID, minGrace, format.Pod(pod))
+		}
 	}
 
 	klog.V(2).Infof("Killing container %q with %d second grace period", containerID.String(), gracePeriod)
@@ -613,6 +627,12 @@ func (m *kubeGenericRuntimeManager) killContainer(pod *v1.Pod, containerID kubec
 		klog.Errorf("Container %q termination failed with gracePeriod %d: %v", containerID.String(), gracePeriod, err)
 	} else {
 		klog.V(3).Infof("Container %q exited normally", containerID.String())
+		// Run internal post-stop lifecycle hook as part of the kill, so that
+		// resources held by the old container (e.g. RT utilization on its
+		// cpus) are released before a replacement container is admitted.
+		if err := m.internalLifecycle.PostStopContainer(containerID.ID); err != nil {
+			klog.Errorf("internal container post-stop lifecycle hook failed for container %q: %v", containerID.String(), err)
+		}
 	}
 
 	m.containerRefManager.ClearRef(containerID)
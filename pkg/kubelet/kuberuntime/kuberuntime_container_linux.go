@@ -78,10 +78,13 @@ func (m *kubeGenericRuntimeManager) generateLinuxContainerConfig(container *v1.C
 		lc.Resources.CpuPeriod = cpuPeriod
 	}
 
-	// TODO(stefano.fiori): add check on m.rtHcsb container.Resources.Requests.Period().IsZero()
-	lc.Resources.CpuRtPeriod = container.Resources.Requests.CpuRtPeriod().Value()
-	lc.Resources.CpuRtRuntime = container.Resources.Requests.CpuRtRuntime().Value()
-	lc.Resources.CpuRt = container.Resources.Requests.CpuRt().Value()
+	// containers without an RT runtime request keep the RT fields at zero, so
+	// the runtime applies no realtime bandwidth to them
+	if !container.Resources.Requests.CpuRtRuntime().IsZero() {
+		lc.Resources.CpuRtPeriod = container.Resources.Requests.CpuRtPeriod().Value()
+		lc.Resources.CpuRtRuntime = container.Resources.Requests.CpuRtRuntime().Value()
+		lc.Resources.CpuRt = container.Resources.Requests.CpuRt().Value()
+	}
 
 	return lc
 }
@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 )
@@ -135,3 +136,46 @@ func TestGenerateContainerConfig(t *testing.T) {
 	_, _, err = m.generateContainerConfig(&podWithContainerSecurityContext.Spec.Containers[0], podWithContainerSecurityContext, 0, "", podWithContainerSecurityContext.Spec.Containers[0].Image, []string{})
 	assert.Error(t, err, "RunAsNonRoot should fail for non-numeric username")
 }
+
+func TestGenerateLinuxContainerConfigRtResources(t *testing.T) {
+	_, _, m, err := createTestRuntimeManager()
+	assert.NoError(t, err)
+	m.machineInfo.MemoryCapacity = 8e9
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "12345678",
+			Name:      "bar",
+			Namespace: "new",
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "rt",
+					Image: "busybox",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+							v1.ResourceRtRuntime: resource.MustParse("100000"),
+							v1.ResourceRtCpu:     resource.MustParse("2"),
+						},
+					},
+				},
+				{
+					Name:  "non-rt",
+					Image: "busybox",
+				},
+			},
+		},
+	}
+
+	rtConfig := m.generateLinuxContainerConfig(&pod.Spec.Containers[0], pod, new(int64), "")
+	assert.Equal(t, int64(1000000), rtConfig.GetResources().GetCpuRtPeriod(), "RT period should reach the CRI resources")
+	assert.Equal(t, int64(100000), rtConfig.GetResources().GetCpuRtRuntime(), "RT runtime should reach the CRI resources")
+	assert.Equal(t, int64(2), rtConfig.GetResources().GetCpuRt(), "RT cpus should reach the CRI resources")
+
+	nonRtConfig := m.generateLinuxContainerConfig(&pod.Spec.Containers[1], pod, new(int64), "")
+	assert.Zero(t, nonRtConfig.GetResources().GetCpuRtPeriod(), "non-RT containers should carry no RT period")
+	assert.Zero(t, nonRtConfig.GetResources().GetCpuRtRuntime(), "non-RT containers should carry no RT runtime")
+	assert.Zero(t, nonRtConfig.GetResources().GetCpuRt(), "non-RT containers should carry no RT cpus")
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package kuberuntime
 
 import (
+	"bytes"
+	"flag"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -24,10 +26,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/api/core/v1"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	"k8s.io/klog"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
 	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
@@ -334,3 +339,137 @@ func TestLifeCycleHook(t *testing.T) {
 		}
 	})
 }
+
+// TestStartContainerLogsRtAssignment tests that the container creation log of
+// an RT container carries the cpuset and the RT utilization the CPU manager
+// assigned to it.
+func TestStartContainerLogsRtAssignment(t *testing.T) {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	fs.Set("logtostderr", "false")
+	defer fs.Set("logtostderr", "true")
+	var buf bytes.Buffer
+	klog.SetOutput(&buf)
+
+	fakeRuntime, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+	// the RT pod is burstable, so the OOM score computation needs a memory capacity
+	m.machineInfo.MemoryCapacity = 8 * 1024 * 1024 * 1024
+	m.internalLifecycle = &recordingLifecycle{
+		rtCpus: cpuset.NewCPUSet(1, 2),
+		rtUtil: 0.25,
+		rtOk:   true,
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "12345678",
+			Name:      "bar",
+			Namespace: "new",
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:            "foo",
+					Image:           "busybox",
+					ImagePullPolicy: v1.PullIfNotPresent,
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+							v1.ResourceRtRuntime: resource.MustParse("250000"),
+							v1.ResourceRtCpu:     resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fakeSandBox, _ := makeAndSetFakePod(t, m, fakeRuntime, pod)
+	fakeSandBoxConfig, err := m.generatePodSandboxConfig(pod, 0)
+	require.NoError(t, err)
+	container := &pod.Spec.Containers[0]
+	podStatus := &kubecontainer.PodStatus{
+		ContainerStatuses: []*kubecontainer.ContainerStatus{
+			{
+				ID: kubecontainer.ContainerID{
+					Type: "docker",
+					ID:   container.Name,
+				},
+				Name:      container.Name,
+				State:     kubecontainer.ContainerStateCreated,
+				CreatedAt: time.Unix(0, time.Now().Unix()),
+			},
+		},
+	}
+
+	_, err = m.startContainer(fakeSandBox.Id, fakeSandBoxConfig, container, pod, podStatus, nil, "", []string{})
+	require.NoError(t, err)
+	klog.Flush()
+
+	log := buf.String()
+	if !strings.Contains(log, `cpuset "1-2"`) {
+		t.Errorf("start log does not report the assigned cpuset, log: %q", log)
+	}
+	if !strings.Contains(log, "RT utilization 0.25") {
+		t.Errorf("start log does not report the assigned RT utilization, log: %q", log)
+	}
+}
+
+// TestKillContainerRtMinGracePeriod tests that a hard kill path overriding
+// the grace period cannot cut below the minimum shutdown window an RT pod
+// requested through RtMinGracePeriodAnnotation.
+func TestKillContainerRtMinGracePeriod(t *testing.T) {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	fs.Set("logtostderr", "false")
+	fs.Set("v", "2")
+	defer fs.Set("logtostderr", "true")
+	var buf bytes.Buffer
+	klog.SetOutput(&buf)
+
+	fakeRuntime, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+	m.machineInfo.MemoryCapacity = 8 * 1024 * 1024 * 1024
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "12345678",
+			Name:      "bar",
+			Namespace: "new",
+			Annotations: map[string]string{
+				RtMinGracePeriodAnnotation: "10",
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:            "foo",
+					Image:           "busybox",
+					ImagePullPolicy: v1.PullIfNotPresent,
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+							v1.ResourceRtRuntime: resource.MustParse("100000"),
+							v1.ResourceRtCpu:     resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, fakeContainers := makeAndSetFakePod(t, m, fakeRuntime, pod)
+	require.Equal(t, 1, len(fakeContainers))
+
+	// an eviction style hard kill overrides the grace period with zero
+	gracePeriodOverride := int64(0)
+	cID := kubecontainer.ContainerID{Type: "docker", ID: fakeContainers[0].Id}
+	err = m.killContainer(pod, cID, "foo", "testKill", &gracePeriodOverride)
+	require.NoError(t, err)
+	klog.Flush()
+
+	if !strings.Contains(buf.String(), "with 10 second grace period") {
+		t.Errorf("killContainer did not honor the RT minimum grace period, log: %q", buf.String())
+	}
+}
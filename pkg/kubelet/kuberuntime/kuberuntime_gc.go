@@ -59,6 +59,9 @@ type containerGCInfo struct {
 	// If true, the container is in unknown state. Garbage collector should try
 	// to stop containers before removal.
 	unknown bool
+	// If true, the container still holds an RT assignment in the cpu manager
+	// and keeps RT bandwidth reserved until it is removed.
+	rt bool
 }
 
 // sandboxGCInfo is the internal information kept for sandboxes being considered for GC.
@@ -103,6 +106,20 @@ func (a byCreated) Len() int           { return len(a) }
 func (a byCreated) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byCreated) Less(i, j int) bool { return a[i].createTime.After(a[j].createTime) }
 
+// Newest first, with the RT containers last so they are removed first: a dead
+// RT container keeps its bandwidth reserved until removal, so when containers
+// have to go anyway the RT ones free the most.
+type byRtThenCreated []containerGCInfo
+
+func (a byRtThenCreated) Len() int      { return len(a) }
+func (a byRtThenCreated) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byRtThenCreated) Less(i, j int) bool {
+	if a[i].rt != a[j].rt {
+		return !a[i].rt
+	}
+	return a[i].createTime.After(a[j].createTime)
+}
+
 // Newest first.
 type sandboxByCreated []sandboxGCInfo
 
@@ -197,11 +214,13 @@ func (cgc *containerGC) evictableContainers(minAge time.Duration) (containersByE
 		}
 
 		labeledInfo := getContainerInfoFromLabels(container.Labels)
+		_, _, rt := cgc.manager.internalLifecycle.GetRtCPUSetAndUtil(string(labeledInfo.PodUID), container.Metadata.Name)
 		containerInfo := containerGCInfo{
 			id:         container.Id,
 			name:       container.Metadata.Name,
 			createTime: createdAt,
 			unknown:    container.State == runtimeapi.ContainerState_CONTAINER_UNKNOWN,
+			rt:         rt,
 		}
 		key := evictUnit{
 			uid:  labeledInfo.PodUID,
@@ -257,7 +276,7 @@ func (cgc *containerGC) evictContainers(gcPolicy kubecontainer.ContainerGCPolicy
 			for key := range evictUnits {
 				flattened = append(flattened, evictUnits[key]...)
 			}
-			sort.Sort(byCreated(flattened))
+			sort.Sort(byRtThenCreated(flattened))
 
 			cgc.removeOldestN(flattened, numContainers-gcPolicy.MaxContainers)
 		}
@@ -26,6 +26,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	"k8s.io/kubernetes/pkg/kubelet/cm"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
 )
@@ -448,6 +450,76 @@ func TestPodLogDirectoryGC(t *testing.T) {
 	assert.Empty(t, fakeOS.Removes)
 }
 
+// rtHoldingLifecycle reports an RT assignment for the containers listed in
+// rtContainers, keyed by podUID/containerName.
+type rtHoldingLifecycle struct {
+	cm.InternalContainerLifecycle
+	rtContainers map[string]bool
+}
+
+func (l *rtHoldingLifecycle) GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool) {
+	if l.rtContainers[podUID+"/"+containerName] {
+		return cpuset.NewCPUSet(1), 0.5, true
+	}
+	return cpuset.CPUSet{}, 0, false
+}
+
+func TestContainerGCRtPriority(t *testing.T) {
+	fakeRuntime, _, m, err := createTestRuntimeManager()
+	assert.NoError(t, err)
+
+	podStateProvider := m.containerGC.podStateProvider.(*fakePodStateProvider)
+	m.internalLifecycle = &rtHoldingLifecycle{
+		InternalContainerLifecycle: cm.NewFakeInternalContainerLifecycle(),
+		rtContainers: map[string]bool{
+			"rtfoo/bar":  true,
+			"rtfoo1/bar": true,
+		},
+	}
+
+	for c, test := range []struct {
+		description string                          // description of the test case
+		containers  []containerTemplate             // templates of containers
+		policy      kubecontainer.ContainerGCPolicy // container gc policy
+		remain      []int                           // template indexes of remaining containers
+	}{
+		{
+			description: "dead RT containers should be removed before older non RT ones when over the limit",
+			containers: []containerTemplate{
+				makeGCContainer(podStateProvider, "foo", "bar", 0, 1, runtimeapi.ContainerState_CONTAINER_EXITED),
+				makeGCContainer(podStateProvider, "rtfoo", "bar", 0, 2, runtimeapi.ContainerState_CONTAINER_EXITED),
+			},
+			policy: kubecontainer.ContainerGCPolicy{MinAge: time.Minute, MaxPerPodContainer: -1, MaxContainers: 1},
+			remain: []int{0},
+		},
+		{
+			description: "oldest first among the dead RT containers",
+			containers: []containerTemplate{
+				makeGCContainer(podStateProvider, "foo", "bar", 0, 3, runtimeapi.ContainerState_CONTAINER_EXITED),
+				makeGCContainer(podStateProvider, "rtfoo", "bar", 0, 2, runtimeapi.ContainerState_CONTAINER_EXITED),
+				makeGCContainer(podStateProvider, "rtfoo1", "bar", 0, 1, runtimeapi.ContainerState_CONTAINER_EXITED),
+			},
+			policy: kubecontainer.ContainerGCPolicy{MinAge: time.Minute, MaxPerPodContainer: -1, MaxContainers: 2},
+			remain: []int{0, 1},
+		},
+	} {
+		t.Logf("TestCase #%d: %+v", c, test)
+		fakeContainers := makeFakeContainers(t, m, test.containers)
+		fakeRuntime.SetFakeContainers(fakeContainers)
+
+		err := m.containerGC.evictContainers(test.policy, true, false)
+		assert.NoError(t, err)
+		realRemain, err := fakeRuntime.ListContainers(nil)
+		assert.NoError(t, err)
+		assert.Len(t, realRemain, len(test.remain))
+		for _, remain := range test.remain {
+			status, err := fakeRuntime.ContainerStatus(fakeContainers[remain].Id)
+			assert.NoError(t, err)
+			assert.Equal(t, &fakeContainers[remain].ContainerStatus, status)
+		}
+	}
+}
+
 func TestUnknownStateContainerGC(t *testing.T) {
 	fakeRuntime, _, m, err := createTestRuntimeManager()
 	assert.NoError(t, err)
@@ -60,6 +60,15 @@ const (
 	podLogsRootDirectory = "/var/log/pods"
 	// A minimal shutdown window for avoiding unnecessary SIGKILLs
 	minimumGracePeriodInSeconds = 2
+	// RtMinGracePeriodAnnotation lets an RT pod ask for a minimum graceful
+	// shutdown window in seconds, honored even when a hard kill path
+	// overrides the grace period (e.g. eviction), so tightly-coupled RT
+	// control loops can reach a consistent state before SIGKILL. The value
+	// is capped at maxRtMinGracePeriodSeconds.
+	RtMinGracePeriodAnnotation = "kubernetes.io/rt-min-grace-period"
+	// The largest shutdown window an RT pod can hold under a grace period
+	// override, so a misconfigured annotation cannot stall eviction.
+	maxRtMinGracePeriodSeconds = 30
 
 	// The expiration time of version cache.
 	versionCacheTTL = 60 * time.Second
@@ -496,6 +505,19 @@ func (m *kubeGenericRuntimeManager) computePodActions(pod *v1.Pod, podStatus *ku
 	// If we need to (re-)create the pod sandbox, everything will need to be
 	// killed and recreated, and init containers should be purged.
 	if createPodSandbox {
+		// Containers that already exited will not go through killContainer, so
+		// run their post-stop lifecycle hook here. This releases any resources
+		// they still hold (e.g. RT utilization on their cpus) before the
+		// replacement containers are admitted in the new sandbox.
+		for _, containerStatus := range podStatus.ContainerStatuses {
+			if containerStatus.State == kubecontainer.ContainerStateRunning {
+				continue
+			}
+			if err := m.internalLifecycle.PostStopContainer(containerStatus.ID.ID); err != nil {
+				klog.Errorf("internal container post-stop lifecycle hook failed for container %v in pod %v with error %v",
+					containerStatus.Name, pod.Name, err)
+			}
+		}
 		if !shouldRestartOnFailure(pod) && attempt != 0 && len(podStatus.ContainerStatuses) != 0 {
 			// Should not restart the pod, just return.
 			// we should not create a sandbox for a pod if it is already done.
@@ -561,6 +583,21 @@ func (m *kubeGenericRuntimeManager) computePodActions(pod *v1.Pod, podStatus *ku
 		return changes
 	}
 
+	// Initialization is complete, but exited init containers may still hold
+	// resources (e.g. RT utilization on their cpus). Release them before
+	// inspecting the app containers, so the app containers are admitted
+	// against the freed capacity.
+	for i := range pod.Spec.InitContainers {
+		containerStatus := podStatus.FindContainerStatusByName(pod.Spec.InitContainers[i].Name)
+		if containerStatus == nil || containerStatus.State == kubecontainer.ContainerStateRunning {
+			continue
+		}
+		if err := m.internalLifecycle.PostStopContainer(containerStatus.ID.ID); err != nil {
+			klog.Errorf("internal container post-stop lifecycle hook failed for init container %v in pod %v with error %v",
+				pod.Spec.InitContainers[i].Name, pod.Name, err)
+		}
+	}
+
 	// Number of running containers to keep.
 	keepCount := 0
 	// check the status of containers.
@@ -636,6 +673,18 @@ func (m *kubeGenericRuntimeManager) computePodActions(pod *v1.Pod, podStatus *ku
 
 	if keepCount == 0 && len(changes.ContainersToStart) == 0 {
 		changes.KillPod = true
+		// Every container is dead and none restarts, so the pod is torn down
+		// without its containers passing through the kill path. Release the RT
+		// utilization they may still hold before the pod cgroup goes away;
+		// this also covers statuses no spec container claims anymore.
+		for _, containerStatus := range podStatus.ContainerStatuses {
+			if containerStatus.State == kubecontainer.ContainerStateRunning {
+				continue
+			}
+			if err := m.internalLifecycle.ReleaseExitedContainer(containerStatus.ID.ID); err != nil {
+				klog.Errorf("Unable to release resources of exited container %q: %v", containerStatus.ID.ID, err)
+			}
+		}
 	}
 
 	return changes
@@ -835,6 +884,11 @@ func (m *kubeGenericRuntimeManager) SyncPod(pod *v1.Pod, podStatus *kubecontaine
 	}
 
 	// Step 7: start containers in podContainerChanges.ContainersToStart.
+	// Start the highest-utilization RT containers first, so that later ones
+	// do not fail admission because the cpus are already fragmented.
+	if hasRtContainers(pod) {
+		sortContainersToStartByRtUtil(pod, podContainerChanges.ContainersToStart)
+	}
 	for _, idx := range podContainerChanges.ContainersToStart {
 		start("container", &pod.Spec.Containers[idx])
 	}
@@ -862,9 +916,19 @@ func (m *kubeGenericRuntimeManager) doBackOff(pod *v1.Pod, container *v1.Contain
 	ts := cStatus.FinishedAt
 	// backOff requires a unique key to identify the container.
 	key := getStableKey(pod, container)
+	eventReason := events.BackOffStartContainer
+	eventMessage := "Back-off restarting failed container"
+	if isRtContainer(container) && cStatus.StartedAt.IsZero() {
+		// the RT container never ran, so it was rejected at admission (e.g.
+		// no RT capacity left); keep its backoff separate from crash backoff
+		// and report it distinctly
+		key = key + "_rt-admission"
+		eventReason = events.BackOffRtAdmission
+		eventMessage = "Back-off re-admitting real-time container"
+	}
 	if backOff.IsInBackOffSince(key, ts) {
 		if ref, err := kubecontainer.GenerateContainerRef(pod, container); err == nil {
-			m.recorder.Eventf(ref, v1.EventTypeWarning, events.BackOffStartContainer, "Back-off restarting failed container")
+			m.recorder.Eventf(ref, v1.EventTypeWarning, eventReason, eventMessage)
 		}
 		err := fmt.Errorf("back-off %s restarting failed container=%s pod=%s", backOff.Get(key), container.Name, format.Pod(pod))
 		klog.V(3).Infof("%s", err.Error())
@@ -962,6 +1026,18 @@ func (m *kubeGenericRuntimeManager) GetPodStatus(uid kubetypes.UID, name, namesp
 	}
 	m.logReduction.ClearID(podFullName)
 
+	// A crashed real-time container keeps holding its RT utilization until
+	// the CPU manager reconciles; release it as soon as the exit is observed
+	// so a replacement can be admitted right away.
+	for _, containerStatus := range containerStatuses {
+		if containerStatus.State != kubecontainer.ContainerStateExited {
+			continue
+		}
+		if err := m.internalLifecycle.ReleaseExitedContainer(containerStatus.ID.ID); err != nil {
+			klog.Errorf("Unable to release resources of exited container %q: %v", containerStatus.ID.ID, err)
+		}
+	}
+
 	return &kubecontainer.PodStatus{
 		ID:                uid,
 		Name:              name,
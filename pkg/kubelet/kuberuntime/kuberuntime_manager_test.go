@@ -28,17 +28,22 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/kubernetes/pkg/kubelet/events"
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	apitest "k8s.io/cri-api/pkg/apis/testing"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	"k8s.io/kubernetes/pkg/credentialprovider"
 	"k8s.io/kubernetes/pkg/features"
+	"k8s.io/kubernetes/pkg/kubelet/cm"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
 	proberesults "k8s.io/kubernetes/pkg/kubelet/prober/results"
@@ -1343,3 +1348,305 @@ func makeBasePodAndStatusWithInitAndEphemeralContainers() (*v1.Pod, *kubecontain
 	})
 	return pod, status
 }
+
+// recordingLifecycle records the internal lifecycle hooks invoked during a
+// sync, so tests can assert that kept containers are left alone.
+type recordingLifecycle struct {
+	postStopCalls []string
+	releaseCalls  []string
+	sandboxCalls  []string
+	rtCpus        cpuset.CPUSet
+	rtUtil        float64
+	rtOk          bool
+}
+
+func (l *recordingLifecycle) PreStartContainer(pod *v1.Pod, container *v1.Container, containerID string) (cm.PreStartContainerResult, error) {
+	return cm.PreStartContainerResult{Rt: l.rtOk, RtCPUSet: l.rtCpus, RtUtil: l.rtUtil}, nil
+}
+
+func (l *recordingLifecycle) PreStopContainer(containerID string) error {
+	return nil
+}
+
+func (l *recordingLifecycle) PostStopContainer(containerID string) error {
+	l.postStopCalls = append(l.postStopCalls, containerID)
+	return nil
+}
+
+func (l *recordingLifecycle) ReleaseExitedContainer(containerID string) error {
+	l.releaseCalls = append(l.releaseCalls, containerID)
+	return nil
+}
+
+func (l *recordingLifecycle) PreCreatePodSandbox(pod *v1.Pod) error {
+	l.sandboxCalls = append(l.sandboxCalls, pod.Name)
+	return nil
+}
+
+func (l *recordingLifecycle) GetRtCPUSetAndUtil(podUID, containerName string) (cpuset.CPUSet, float64, bool) {
+	return l.rtCpus, l.rtUtil, l.rtOk
+}
+
+func TestComputePodActionsSteadyStateRtPod(t *testing.T) {
+	_, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+
+	lifecycle := &recordingLifecycle{}
+	m.internalLifecycle = lifecycle
+
+	pod, status := makeBasePodAndStatus()
+	// the first container is a running RT container
+	pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+			v1.ResourceRtRuntime: resource.MustParse("100000"),
+			v1.ResourceRtCpu:     resource.MustParse("1"),
+		},
+	}
+	status.ContainerStatuses[0].Hash = kubecontainer.HashContainer(&pod.Spec.Containers[0])
+
+	actions := m.computePodActions(pod, status)
+
+	noAction := podActions{
+		SandboxID:         status.SandboxStatuses[0].Id,
+		ContainersToStart: []int{},
+		ContainersToKill:  map[kubecontainer.ContainerID]containerToKillInfo{},
+	}
+	verifyActions(t, &noAction, &actions, "steady-state RT pod")
+
+	if len(lifecycle.postStopCalls) != 0 {
+		t.Errorf("post-stop lifecycle hook invoked for kept RT containers: %v", lifecycle.postStopCalls)
+	}
+}
+
+func TestComputePodActionsRtPodSandboxRecreation(t *testing.T) {
+	_, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+
+	lifecycle := &recordingLifecycle{}
+	m.internalLifecycle = lifecycle
+
+	pod, status := makeBasePodAndStatus()
+	pod.Spec.RestartPolicy = v1.RestartPolicyAlways
+	// the first container is an RT container
+	pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+			v1.ResourceRtRuntime: resource.MustParse("100000"),
+			v1.ResourceRtCpu:     resource.MustParse("1"),
+		},
+	}
+	status.ContainerStatuses[0].Hash = kubecontainer.HashContainer(&pod.Spec.Containers[0])
+
+	// the sandbox died, e.g. because of a network namespace change
+	status.SandboxStatuses[0].State = runtimeapi.PodSandboxState_SANDBOX_NOTREADY
+	for i := range status.ContainerStatuses {
+		status.ContainerStatuses[i].State = kubecontainer.ContainerStateExited
+	}
+
+	actions := m.computePodActions(pod, status)
+
+	// everything must be recreated, including the RT container, so its RT
+	// allocation goes through admission again
+	expected := podActions{
+		KillPod:           true,
+		CreateSandbox:     true,
+		SandboxID:         status.SandboxStatuses[0].Id,
+		Attempt:           uint32(1),
+		ContainersToStart: []int{0, 1, 2},
+		ContainersToKill:  map[kubecontainer.ContainerID]containerToKillInfo{},
+	}
+	verifyActions(t, &expected, &actions, "RT pod sandbox recreation")
+
+	// the dead RT container must have been released through the post-stop
+	// hook before the replacement is admitted
+	found := false
+	for _, id := range lifecycle.postStopCalls {
+		if id == status.ContainerStatuses[0].ID.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("post-stop lifecycle hook not invoked for the dead RT container, got calls: %v", lifecycle.postStopCalls)
+	}
+}
+
+func TestComputePodActionsKillPodReleasesRt(t *testing.T) {
+	_, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+
+	lifecycle := &recordingLifecycle{}
+	m.internalLifecycle = lifecycle
+
+	pod, status := makeBasePodAndStatus()
+	pod.Spec.RestartPolicy = v1.RestartPolicyNever
+	// the first container is an RT container
+	pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+			v1.ResourceRtRuntime: resource.MustParse("100000"),
+			v1.ResourceRtCpu:     resource.MustParse("1"),
+		},
+	}
+	status.ContainerStatuses[0].Hash = kubecontainer.HashContainer(&pod.Spec.Containers[0])
+
+	// every container died and none restarts: the pod goes down as a whole
+	for i := range status.ContainerStatuses {
+		status.ContainerStatuses[i].State = kubecontainer.ContainerStateExited
+	}
+
+	actions := m.computePodActions(pod, status)
+
+	expected := podActions{
+		KillPod:           true,
+		SandboxID:         status.SandboxStatuses[0].Id,
+		ContainersToStart: []int{},
+		ContainersToKill:  map[kubecontainer.ContainerID]containerToKillInfo{},
+	}
+	verifyActions(t, &expected, &actions, "all containers dead")
+
+	// the teardown must have released the RT utilization of the dead RT
+	// container, it will not pass through the kill path anymore
+	found := false
+	for _, id := range lifecycle.releaseCalls {
+		if id == status.ContainerStatuses[0].ID.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("release not invoked for the dead RT container, got calls: %v", lifecycle.releaseCalls)
+	}
+}
+
+func TestGetPodStatusReleasesExitedRtContainer(t *testing.T) {
+	fakeRuntime, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+	// RT containers are burstable, the oom score heuristic needs a capacity
+	m.machineInfo.MemoryCapacity = 8e9
+
+	lifecycle := &recordingLifecycle{}
+	m.internalLifecycle = lifecycle
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "12345678",
+			Name:      "rt-foo",
+			Namespace: "new",
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:            "rt1",
+					Image:           "busybox",
+					ImagePullPolicy: v1.PullIfNotPresent,
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+							v1.ResourceRtRuntime: resource.MustParse("100000"),
+							v1.ResourceRtCpu:     resource.MustParse("1"),
+						},
+					},
+				},
+				{
+					Name:            "foo2",
+					Image:           "busybox",
+					ImagePullPolicy: v1.PullIfNotPresent,
+				},
+			},
+		},
+	}
+	makeAndSetFakePod(t, m, fakeRuntime, pod)
+
+	// the RT container crashes
+	exitedID := ""
+	for _, c := range fakeRuntime.Containers {
+		if c.Metadata.Name == "rt1" {
+			c.State = runtimeapi.ContainerState_CONTAINER_EXITED
+			exitedID = c.Id
+		}
+	}
+	require.NotEmpty(t, exitedID)
+
+	_, err = m.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	assert.NoError(t, err)
+
+	// the exit observed in the status must have triggered the release, and
+	// only for the exited container
+	assert.Equal(t, []string{exitedID}, lifecycle.releaseCalls)
+}
+
+func TestDoBackOffRtAdmissionEventReason(t *testing.T) {
+	_, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	m.recorder = recorder
+
+	pod, status := makeBasePodAndStatus()
+	pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+			v1.ResourceRtRuntime: resource.MustParse("100000"),
+			v1.ResourceRtCpu:     resource.MustParse("1"),
+		},
+	}
+	// the RT container was rejected at admission: it exited without ever
+	// having started
+	status.ContainerStatuses[0].State = kubecontainer.ContainerStateExited
+	status.ContainerStatuses[0].StartedAt = time.Time{}
+	status.ContainerStatuses[0].FinishedAt = time.Now()
+	// the second container crashed after running
+	status.ContainerStatuses[1].State = kubecontainer.ContainerStateExited
+	status.ContainerStatuses[1].StartedAt = time.Now().Add(-time.Minute)
+	status.ContainerStatuses[1].FinishedAt = time.Now()
+
+	backOff := flowcontrol.NewBackOff(time.Second, time.Minute)
+
+	// the first observation arms the backoff, the second reports it
+	_, _, err = m.doBackOff(pod, &pod.Spec.Containers[0], status, backOff)
+	assert.NoError(t, err)
+	isInBackOff, _, err := m.doBackOff(pod, &pod.Spec.Containers[0], status, backOff)
+	assert.True(t, isInBackOff)
+	assert.Error(t, err)
+	select {
+	case event := <-recorder.Events:
+		assert.Contains(t, event, events.BackOffRtAdmission)
+	default:
+		t.Fatal("no event recorded for RT admission backoff")
+	}
+
+	// the crashed non-RT container keeps the generic reason
+	m.doBackOff(pod, &pod.Spec.Containers[1], status, backOff)
+	isInBackOff, _, err = m.doBackOff(pod, &pod.Spec.Containers[1], status, backOff)
+	assert.True(t, isInBackOff)
+	assert.Error(t, err)
+	select {
+	case event := <-recorder.Events:
+		assert.Contains(t, event, events.BackOffStartContainer)
+		assert.NotContains(t, event, events.BackOffRtAdmission)
+	default:
+		t.Fatal("no event recorded for crash backoff")
+	}
+}
+
+func TestComputePodActionsReleasesExitedInitContainers(t *testing.T) {
+	_, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+
+	lifecycle := &recordingLifecycle{}
+	m.internalLifecycle = lifecycle
+
+	// All the init containers exited, the app containers are about to start:
+	// the exited init containers must go through the post-stop hook before
+	// the app containers are admitted.
+	pod, status := makeBasePodAndStatusWithInitContainers()
+	actions := m.computePodActions(pod, status)
+
+	if len(actions.ContainersToStart) == 0 {
+		t.Fatalf("expected app containers to start, got %+v", actions)
+	}
+	expected := []string{"initid1", "initid2", "initid3"}
+	if !reflect.DeepEqual(lifecycle.postStopCalls, expected) {
+		t.Errorf("post-stop hook calls = %v, want %v", lifecycle.postStopCalls, expected)
+	}
+}
@@ -140,6 +140,14 @@ func (m *kubeGenericRuntimeManager) generatePodSandboxConfig(pod *v1.Pod, attemp
 // generatePodSandboxLinuxConfig generates LinuxPodSandboxConfig from v1.Pod.
 func (m *kubeGenericRuntimeManager) generatePodSandboxLinuxConfig(pod *v1.Pod) (*runtimeapi.LinuxPodSandboxConfig, error) {
 	cgroupParent := m.runtimeHelper.GetPodCgroupParent(pod)
+	if hasRtContainers(pod) {
+		// The pod cgroup must exist with the RT period applied before the
+		// sandbox starts, or the RT runtimes cannot be written once the
+		// containers come up.
+		if err := m.internalLifecycle.PreCreatePodSandbox(pod); err != nil {
+			return nil, err
+		}
+	}
 	lc := &runtimeapi.LinuxPodSandboxConfig{
 		CgroupParent: cgroupParent,
 		SecurityContext: &runtimeapi.LinuxSandboxSecurityContext{
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
@@ -113,3 +114,30 @@ func newTestPod() *v1.Pod {
 		},
 	}
 }
+
+// TestGeneratePodSandboxConfigRtPodCgroup tests that the pod level cgroup of
+// an RT pod is prepared before the sandbox config is handed to the runtime.
+func TestGeneratePodSandboxConfigRtPodCgroup(t *testing.T) {
+	_, _, m, err := createTestRuntimeManager()
+	require.NoError(t, err)
+
+	lifecycle := &recordingLifecycle{}
+	m.internalLifecycle = lifecycle
+
+	pod := newTestPod()
+	_, err = m.generatePodSandboxConfig(pod, 1)
+	require.NoError(t, err)
+	assert.Empty(t, lifecycle.sandboxCalls, "non RT pods must not prepare an RT pod cgroup")
+
+	rtPod := newTestPod()
+	rtPod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+			v1.ResourceRtRuntime: resource.MustParse("100000"),
+			v1.ResourceRtCpu:     resource.MustParse("1"),
+		},
+	}
+	_, err = m.generatePodSandboxConfig(rtPod, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{rtPod.Name}, lifecycle.sandboxCalls)
+}
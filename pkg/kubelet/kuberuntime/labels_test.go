@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
@@ -237,3 +238,37 @@ func TestPodAnnotations(t *testing.T) {
 		t.Errorf("expected %v, got %v", expected, podSandboxInfo)
 	}
 }
+
+func TestContainerAnnotationsRestartCountRtContainer(t *testing.T) {
+	container := &v1.Container{
+		Name: "test_rt_container",
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+				v1.ResourceRtRuntime: resource.MustParse("100000"),
+				v1.ResourceRtCpu:     resource.MustParse("1"),
+			},
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test_pod",
+			Namespace: "test_pod_namespace",
+			UID:       "test_pod_uid",
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{*container},
+		},
+	}
+	opts := &kubecontainer.RunContainerOptions{}
+
+	// restart counts must keep incrementing through the annotations round
+	// trip for RT containers, just like for any other container
+	for restartCount := 0; restartCount < 3; restartCount++ {
+		annotations := newContainerAnnotations(container, pod, restartCount, opts)
+		containerInfo := getContainerInfoFromAnnotations(annotations)
+		if containerInfo.RestartCount != restartCount {
+			t.Errorf("restart count from annotations = %d, want %d", containerInfo.RestartCount, restartCount)
+		}
+	}
+}
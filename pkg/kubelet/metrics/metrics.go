@@ -87,6 +87,12 @@ const (
 	// Metrics keys for RuntimeClass
 	RunPodSandboxDurationKey = "run_podsandbox_duration_seconds"
 	RunPodSandboxErrorsKey   = "run_podsandbox_errors_total"
+
+	// Metric keys for real-time cpu accounting
+	CPURtUtilizationDriftKey       = "cpu_rt_utilization_drift"
+	CPURtSaturatedCountKey         = "cpu_rt_saturated_count"
+	ContainerRtUtilizationKey      = "container_rt_utilization"
+	CPUManagerReconcileFailuresKey = "cpu_manager_reconcile_failures_total"
 )
 
 var (
@@ -494,6 +500,51 @@ var (
 		},
 		[]string{"container_state"},
 	)
+	// CPURtUtilizationDrift is a Gauge measuring the difference between the
+	// RT utilization the cpu manager tracks and the one currently applied in
+	// the cgroups. It should stay at zero on a healthy node.
+	CPURtUtilizationDrift = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      KubeletSubsystem,
+			Name:           CPURtUtilizationDriftKey,
+			Help:           "Difference between the cpu manager's tracked RT utilization and the RT utilization applied in the cgroups.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	// CPURtSaturatedCount is a Gauge counting the cpus whose RT utilization
+	// has reached the allocatable per-cpu cap, leaving no RT headroom.
+	CPURtSaturatedCount = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      KubeletSubsystem,
+			Name:           CPURtSaturatedCountKey,
+			Help:           "Number of cpus whose RT utilization has reached the allocatable per-cpu cap, leaving no RT headroom.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	// ContainerRtUtilization is a Gauge tracking the RT utilization each
+	// container reserves, labeled by pod and container name, so dashboards
+	// can attribute the node's RT usage.
+	ContainerRtUtilization = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      KubeletSubsystem,
+			Name:           ContainerRtUtilizationKey,
+			Help:           "RT utilization reserved by each container, labeled by pod and container name.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"pod", "container"},
+	)
+	// CPUManagerReconcileFailures is a Counter counting the containers the cpu
+	// manager failed to reconcile, partitioned by the kind of failure: "rt"
+	// for containers reserving RT cpu time, "generic" for the others.
+	CPUManagerReconcileFailures = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      KubeletSubsystem,
+			Name:           CPUManagerReconcileFailuresKey,
+			Help:           "Cumulative number of containers the cpu manager failed to reconcile, partitioned by the kind of failure.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"kind"},
+	)
 )
 
 var registerMetrics sync.Once
@@ -533,6 +584,10 @@ func Register(containerCache kubecontainer.RuntimeCache, collectors ...metrics.S
 		legacyregistry.MustRegister(DeprecatedDevicePluginAllocationLatency)
 		legacyregistry.MustRegister(RunningContainerCount)
 		legacyregistry.MustRegister(RunningPodCount)
+		legacyregistry.MustRegister(CPURtUtilizationDrift)
+		legacyregistry.MustRegister(CPURtSaturatedCount)
+		legacyregistry.MustRegister(ContainerRtUtilization)
+		legacyregistry.MustRegister(CPUManagerReconcileFailures)
 		if utilfeature.DefaultFeatureGate.Enabled(features.DynamicKubeletConfig) {
 			legacyregistry.MustRegister(AssignedConfig)
 			legacyregistry.MustRegister(ActiveConfig)
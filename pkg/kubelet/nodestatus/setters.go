@@ -17,6 +17,7 @@ limitations under the License.
 package nodestatus
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"net"
@@ -41,6 +42,7 @@ import (
 	"k8s.io/kubernetes/pkg/kubelet/cm"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	"k8s.io/kubernetes/pkg/kubelet/events"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 	"k8s.io/kubernetes/pkg/volume"
 
 	"k8s.io/klog"
@@ -273,6 +275,16 @@ func MachineInfo(nodeName string,
 				if runtimeExists && periodExists {
 					node.Status.Capacity[v1.ResourceRtPeriod] = rtPeriod
 					node.Status.Capacity[v1.ResourceRtRuntime] = rtRuntime
+					rtCpus := int64(0)
+					if rtCpu, exists := capacity[v1.ResourceRtCpu]; exists {
+						node.Status.Capacity[v1.ResourceRtCpu] = rtCpu
+						rtCpus = rtCpu.Value()
+					}
+					// Surface the scaled RT utilization as well, under the
+					// same name and scale the scheduler computes from runtime
+					// and period, so it shows up in kubectl describe node.
+					util := schedulernodeinfo.RtScaledUtilization(rtRuntime.Value(), rtPeriod.Value(), rtCpus)
+					node.Status.Capacity[schedulernodeinfo.ResourceRtUtilization] = *resource.NewQuantity(util, resource.DecimalSI)
 				}
 				if runtimeExists != periodExists {
 					return fmt.Errorf("only one between rtRuntime or rtPeriod resource has been specified")
@@ -462,6 +474,29 @@ func GoRuntime() Setter {
 	}
 }
 
+// RtPerSocketUtilization returns a Setter that publishes the RT utilization
+// allocated on the node, aggregated by socket, as a node annotation. The
+// annotation is left untouched when the cpu manager policy does not manage
+// RT resources.
+func RtPerSocketUtilization(perSocketRtUtilizationFunc func() map[int]float64, // typically Kubelet.containerManager.GetPerSocketRtUtilization
+) Setter {
+	return func(node *v1.Node) error {
+		perSocket := perSocketRtUtilizationFunc()
+		if perSocket == nil {
+			return nil
+		}
+		raw, err := json.Marshal(perSocket)
+		if err != nil {
+			return fmt.Errorf("failed to serialize the per socket RT utilization: %v", err)
+		}
+		if node.ObjectMeta.Annotations == nil {
+			node.ObjectMeta.Annotations = make(map[string]string)
+		}
+		node.ObjectMeta.Annotations[kubeletapis.AnnotationRtPerSocketUtilization] = string(raw)
+		return nil
+	}
+}
+
 // ReadyCondition returns a Setter that updates the v1.NodeReady condition on the node.
 func ReadyCondition(
 	nowFunc func() time.Time, // typically Kubelet.clock.Now
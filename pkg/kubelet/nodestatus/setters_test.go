@@ -42,6 +42,7 @@ import (
 	kubecontainertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
 	"k8s.io/kubernetes/pkg/kubelet/events"
 	"k8s.io/kubernetes/pkg/kubelet/util/sliceutils"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 	"k8s.io/kubernetes/pkg/volume"
 	volumetest "k8s.io/kubernetes/pkg/volume/testing"
 
@@ -407,6 +408,42 @@ func TestMachineInfo(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:    "real-time capacity is carried into capacity and allocatable",
+			node:    &v1.Node{},
+			maxPods: 110,
+			machineInfo: &cadvisorapiv1.MachineInfo{
+				NumCores:       2,
+				MemoryCapacity: 1024,
+			},
+			capacity: v1.ResourceList{
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(950000, resource.DecimalSI),
+				v1.ResourceRtCpu:     *resource.NewQuantity(2, resource.DecimalSI),
+			},
+			expectNode: &v1.Node{
+				Status: v1.NodeStatus{
+					Capacity: v1.ResourceList{
+						v1.ResourceCPU:                          *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						v1.ResourceMemory:                       *resource.NewQuantity(1024, resource.BinarySI),
+						v1.ResourcePods:                         *resource.NewQuantity(110, resource.DecimalSI),
+						v1.ResourceRtPeriod:                     *resource.NewQuantity(1000000, resource.DecimalSI),
+						v1.ResourceRtRuntime:                    *resource.NewQuantity(950000, resource.DecimalSI),
+						v1.ResourceRtCpu:                        *resource.NewQuantity(2, resource.DecimalSI),
+						schedulernodeinfo.ResourceRtUtilization: *resource.NewQuantity(1900000, resource.DecimalSI),
+					},
+					Allocatable: v1.ResourceList{
+						v1.ResourceCPU:                          *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						v1.ResourceMemory:                       *resource.NewQuantity(1024, resource.BinarySI),
+						v1.ResourcePods:                         *resource.NewQuantity(110, resource.DecimalSI),
+						v1.ResourceRtPeriod:                     *resource.NewQuantity(1000000, resource.DecimalSI),
+						v1.ResourceRtRuntime:                    *resource.NewQuantity(950000, resource.DecimalSI),
+						v1.ResourceRtCpu:                        *resource.NewQuantity(2, resource.DecimalSI),
+						schedulernodeinfo.ResourceRtUtilization: *resource.NewQuantity(1900000, resource.DecimalSI),
+					},
+				},
+			},
+		},
 		{
 			desc:        "podsPerCore greater than zero, but less than maxPods/cores",
 			node:        &v1.Node{},
@@ -749,8 +786,8 @@ func TestMachineInfo(t *testing.T) {
 			},
 			machineInfoError:             nil,
 			capacity:                     v1.ResourceList{
-				v1.ResourcePeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
-				v1.ResourceRuntime: *resource.NewQuantity(950000, resource.DecimalSI),
+				v1.ResourceRtPeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(950000, resource.DecimalSI),
 			},
 			devicePluginResourceCapacity: dprc{},
 			nodeAllocatableReservation:   nil,
@@ -761,18 +798,20 @@ func TestMachineInfo(t *testing.T) {
 						SystemUUID: "SystemUUID",
 					},
 					Capacity: v1.ResourceList{
-						v1.ResourceCPU:     *resource.NewMilliQuantity(2000, resource.DecimalSI),
-						v1.ResourceMemory:  *resource.NewQuantity(1024, resource.BinarySI),
-						v1.ResourcePods:    *resource.NewQuantity(110, resource.DecimalSI),
-						v1.ResourcePeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
-						v1.ResourceRuntime: *resource.NewQuantity(950000, resource.DecimalSI),
+						v1.ResourceCPU:                          *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						v1.ResourceMemory:                       *resource.NewQuantity(1024, resource.BinarySI),
+						v1.ResourcePods:                         *resource.NewQuantity(110, resource.DecimalSI),
+						v1.ResourceRtPeriod:                     *resource.NewQuantity(1000000, resource.DecimalSI),
+						v1.ResourceRtRuntime:                    *resource.NewQuantity(950000, resource.DecimalSI),
+						schedulernodeinfo.ResourceRtUtilization: *resource.NewQuantity(950000, resource.DecimalSI),
 					},
 					Allocatable: v1.ResourceList{
-						v1.ResourceCPU:     *resource.NewMilliQuantity(2000, resource.DecimalSI),
-						v1.ResourceMemory:  *resource.NewQuantity(1024, resource.BinarySI),
-						v1.ResourcePods:    *resource.NewQuantity(110, resource.DecimalSI),
-						v1.ResourcePeriod:  *resource.NewQuantity(1000000, resource.DecimalSI),
-						v1.ResourceRuntime: *resource.NewQuantity(950000, resource.DecimalSI),
+						v1.ResourceCPU:                          *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						v1.ResourceMemory:                       *resource.NewQuantity(1024, resource.BinarySI),
+						v1.ResourcePods:                         *resource.NewQuantity(110, resource.DecimalSI),
+						v1.ResourceRtPeriod:                     *resource.NewQuantity(1000000, resource.DecimalSI),
+						v1.ResourceRtRuntime:                    *resource.NewQuantity(950000, resource.DecimalSI),
+						schedulernodeinfo.ResourceRtUtilization: *resource.NewQuantity(950000, resource.DecimalSI),
 					},
 				},
 			},
@@ -780,7 +819,7 @@ func TestMachineInfo(t *testing.T) {
 		},
 	}
 
-	for _, tc := range cases[len(cases)-1:] {
+	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 
 			machineInfoFunc := func() (*cadvisorapiv1.MachineInfo, error) {
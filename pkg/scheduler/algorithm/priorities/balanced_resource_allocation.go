@@ -22,6 +22,7 @@ import (
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/kubernetes/pkg/features"
 	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 )
 
 var (
@@ -43,6 +44,18 @@ func balancedResourceScorer(requested, allocable ResourceToValueMap, includeVolu
 
 	for request := range requested {
 		f := fractionOfCapacity(requested[request], allocable[request])
+		if request == schedulernodeinfo.ResourceRtUtilization {
+			// An RT fraction above 1 means the node reported stale state and
+			// is over-committed; the RT fit predicate has the final word on
+			// feasibility, so rather than zeroing the score clamp the
+			// fraction to 1 and keep ranking such nodes by how balanced the
+			// remaining resources are.
+			if f > 1 {
+				f = 1
+			}
+			fractions = append(fractions, f)
+			continue
+		}
 		if f >= 1 {
 			// if requested >= capacity, the corresponding host should never be preferred.
 			return 0
@@ -55,7 +68,6 @@ func balancedResourceScorer(requested, allocable ResourceToValueMap, includeVolu
 		fractions = append(fractions, volumeFraction)
 	}
 
-	// TODO(stefano.fiori): what to do with utilization resource
 	// Upper and lower boundary of difference between cpuFraction and memoryFraction are -1 and 1
 	// respectively. Multiplying the absolute value of the difference by 10 scales the value to
 	// 0-10 with 0 representing well balanced allocation and 10 poorly balanced. Subtracting it from
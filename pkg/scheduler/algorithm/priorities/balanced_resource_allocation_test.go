@@ -27,6 +27,7 @@ import (
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	"k8s.io/kubernetes/pkg/features"
 	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 	nodeinfosnapshot "k8s.io/kubernetes/pkg/scheduler/nodeinfo/snapshot"
 )
 
@@ -217,8 +218,8 @@ func TestBalancedResourceAllocation(t *testing.T) {
 			{
 				Resources: v1.ResourceRequirements{
 					Requests: v1.ResourceList{
-						v1.ResourcePeriod:  resource.MustParse("10000"),
-						v1.ResourceRuntime: resource.MustParse("1000"),
+						v1.ResourceRtPeriod:  resource.MustParse("10000"),
+						v1.ResourceRtRuntime: resource.MustParse("1000"),
 						v1.ResourceMemory: resource.MustParse("0"),
 						v1.ResourceCPU: resource.MustParse("0m"),
 					},
@@ -227,8 +228,8 @@ func TestBalancedResourceAllocation(t *testing.T) {
 			{
 				Resources: v1.ResourceRequirements{
 					Requests: v1.ResourceList{
-						v1.ResourcePeriod:  resource.MustParse("178000"),
-						v1.ResourceRuntime: resource.MustParse("13000"),
+						v1.ResourceRtPeriod:  resource.MustParse("178000"),
+						v1.ResourceRtRuntime: resource.MustParse("13000"),
 						v1.ResourceMemory: resource.MustParse("0"),
 						v1.ResourceCPU: resource.MustParse("0m"),
 					},
@@ -429,11 +430,11 @@ func TestBalancedResourceAllocation(t *testing.T) {
 			nodes: []*v1.Node{
 				makeNodeWithExtendedResource("machine3", 10000, 40000,
 					map[string]int64{
-						string(v1.ResourcePeriod): 100000, string(v1.ResourceRuntime): 50000,
+						string(v1.ResourceRtPeriod): 100000, string(v1.ResourceRtRuntime): 50000,
 					}),
 				makeNodeWithExtendedResource("machine4", 10000, 40000,
 					map[string]int64{
-						string(v1.ResourcePeriod): 100000, string(v1.ResourceRuntime): 90000,
+						string(v1.ResourceRtPeriod): 100000, string(v1.ResourceRtRuntime): 90000,
 					}),
 			},
 			expectedList: []framework.NodeScore{{Name: "machine3", Score: 97}, {Name: "machine4", Score: 99}},
@@ -445,14 +446,17 @@ func TestBalancedResourceAllocation(t *testing.T) {
 			nodes: []*v1.Node{
 				makeNodeWithExtendedResource("machine3", 10000, 40000,
 					map[string]int64{
-						string(v1.ResourcePeriod): 100000, string(v1.ResourceRuntime): 10,
+						string(v1.ResourceRtPeriod): 100000, string(v1.ResourceRtRuntime): 10,
 					}),
 				makeNodeWithExtendedResource("machine4", 10000, 40000,
 					map[string]int64{
-						string(v1.ResourcePeriod): 100000, string(v1.ResourceRuntime): 90000,
+						string(v1.ResourceRtPeriod): 100000, string(v1.ResourceRtRuntime): 90000,
 					}),
 			},
-			expectedList: []framework.NodeScore{{Name: "machine3", Score: 0}, {Name: "machine4", Score: 99}},
+			// the full machine3 is not zeroed: its RT fraction is clamped to 1
+			// and the node keeps a score from the balance of the remaining
+			// resources, the RT fit predicate rules it out on feasibility
+			expectedList: []framework.NodeScore{{Name: "machine3", Score: 77}, {Name: "machine4", Score: 99}},
 		},
 	}
 
@@ -479,3 +483,30 @@ func TestBalancedResourceAllocation(t *testing.T) {
 		})
 	}
 }
+
+func TestBalancedResourceScorerClampsRtFraction(t *testing.T) {
+	requested := ResourceToValueMap{
+		v1.ResourceCPU:                          500,
+		v1.ResourceMemory:                       500,
+		schedulernodeinfo.ResourceRtUtilization: 2000,
+	}
+	allocable := ResourceToValueMap{
+		v1.ResourceCPU:                          1000,
+		v1.ResourceMemory:                       1000,
+		schedulernodeinfo.ResourceRtUtilization: 1000,
+	}
+
+	// an RT fraction above 1 is clamped to 1, not a disqualification: the
+	// score is the one of a fully RT-saturated node
+	score := balancedResourceScorer(requested, allocable, false, 0, 0)
+	want := int64((1 - variance(0.5, 0.5, 1)) * float64(framework.MaxNodeScore))
+	if score != want {
+		t.Errorf("balancedResourceScorer() with RT fraction 2 = %d, want %d", score, want)
+	}
+
+	// cpu and memory over capacity still zero the score
+	requested[v1.ResourceCPU] = 2000
+	if score := balancedResourceScorer(requested, allocable, false, 0, 0); score != 0 {
+		t.Errorf("balancedResourceScorer() with cpu fraction 2 = %d, want 0", score)
+	}
+}
@@ -44,6 +44,8 @@ func TestPriorityMetadata(t *testing.T) {
 	specifiedPodLimits := &schedulernodeinfo.Resource{}
 	specifiedPodLimits.MilliCPU = 200
 	specifiedPodLimits.Memory = 2000
+	// the 200m cpu limit rounds up to one whole cpu in the RT accounting
+	specifiedPodLimits.RtCpu = 1
 
 	tolerations := []v1.Toleration{{
 		Key:      "foo",
@@ -44,6 +44,11 @@ type ResourceToValueMap map[v1.ResourceName]int64
 // DefaultRequestedRatioResources is used to set default requestToWeight map for CPU and memory
 var DefaultRequestedRatioResources = ResourceToWeightMap{v1.ResourceMemory: 1, v1.ResourceCPU: 1}
 
+// RtUtilizationWeightName is the name a RequestedToCapacityRatioArguments
+// resource entry uses to weight the RT utilization of the node, whose internal
+// resource name is not meaningful in configuration.
+const RtUtilizationWeightName = "rt-utilization"
+
 // PriorityMap priorities nodes according to the resource allocations on the node.
 // It will use `scorer` function to calculate the score.
 func (r *ResourceAllocationPriority) PriorityMap(
@@ -77,6 +82,10 @@ func (r *ResourceAllocationPriority) PriorityMap(
 		score = r.scorer(requested, allocatable, false, 0, 0)
 	}
 	if klog.V(10) {
+		// The RT utilization entry is set only for RT pods, not to perturb the
+		// scorer on ordinary ones. In the log always report it, as 0 when not
+		// applicable, so the maps carry the same keys on every line.
+		allocatable[schedulernodeinfo.ResourceRtUtilization], requested[schedulernodeinfo.ResourceRtUtilization] = allocUtilization, reqUtilization
 		if len(pod.Spec.Volumes) >= 0 && utilfeature.DefaultFeatureGate.Enabled(features.BalanceAttachedNodeVolumes) && nodeInfo.TransientInfo != nil {
 			klog.Infof(
 				"%v -> %v: %v, map of allocatable resources %v, map of requested resources %v , allocatable volumes %d, requested volumes %d, score %d",
@@ -141,6 +150,13 @@ func calculateResourceRTUtilizationAllocatableRequest(nodeInfo *schedulernodeinf
 	return 0, 0
 }
 
+// RTUtilizationAllocatableRequest returns the node's allocatable RT
+// utilization and the RT utilization that would be requested on the node once
+// the pod is placed there. Both are zero for non RT pods.
+func RTUtilizationAllocatableRequest(nodeInfo *schedulernodeinfo.NodeInfo, pod *v1.Pod) (int64, int64) {
+	return calculateResourceRTUtilizationAllocatableRequest(nodeInfo, pod)
+}
+
 // calculatePodResourceRequest returns the total non-zero requests. If Overhead is defined for the pod and the
 // PodOverhead feature is enabled, the Overhead is added to the result.
 func calculatePodResourceRequest(pod *v1.Pod, resource v1.ResourceName) int64 {
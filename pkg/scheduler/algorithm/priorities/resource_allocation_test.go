@@ -0,0 +1,56 @@
+package priorities
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+	nodeinfosnapshot "k8s.io/kubernetes/pkg/scheduler/nodeinfo/snapshot"
+)
+
+// TestResourceAllocationPriorityLogsRtUtilization verifies that the V(10) log
+// always carries the RT utilization entry, even for pods not requesting RT
+// resources.
+func TestResourceAllocationPriorityLogsRtUtilization(t *testing.T) {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	fs.Set("v", "10")
+	fs.Set("logtostderr", "false")
+	defer func() {
+		fs.Set("v", "0")
+		fs.Set("logtostderr", "true")
+	}()
+	var buf bytes.Buffer
+	klog.SetOutput(&buf)
+
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("1000m"),
+							v1.ResourceMemory: resource.MustParse("2000"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nodes := []*v1.Node{makeNode("machine1", 4000, 10000)}
+	snapshot := nodeinfosnapshot.NewSnapshot(nodeinfosnapshot.CreateNodeInfoMap(nil, nodes))
+
+	if _, err := LeastRequestedPriorityMap(pod, nil, snapshot.NodeInfoMap["machine1"]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	klog.Flush()
+
+	if !strings.Contains(buf.String(), string(schedulernodeinfo.ResourceRtUtilization)) {
+		t.Errorf("V(10) log misses the %s entry: %q", schedulernodeinfo.ResourceRtUtilization, buf.String())
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/serviceaffinity"
 	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
 	schedulerlisters "k8s.io/kubernetes/pkg/scheduler/listers"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 	"k8s.io/kubernetes/pkg/scheduler/volumebinder"
 
 	"k8s.io/klog"
@@ -483,9 +484,15 @@ func buildScoringFunctionShapeFromRequestedToCapacityRatioArguments(arguments *s
 		return shape, resourceToWeightMap
 	}
 	for _, resource := range arguments.Resources {
-		resourceToWeightMap[v1.ResourceName(resource.Name)] = resource.Weight
+		name := v1.ResourceName(resource.Name)
+		if resource.Name == priorities.RtUtilizationWeightName {
+			// the configuration addresses the scheduler-internal RT
+			// utilization resource by a stable alias
+			name = schedulernodeinfo.ResourceRtUtilization
+		}
+		resourceToWeightMap[name] = resource.Weight
 		if resource.Weight == 0 {
-			resourceToWeightMap[v1.ResourceName(resource.Name)] = 1
+			resourceToWeightMap[name] = 1
 		}
 	}
 	return shape, resourceToWeightMap
@@ -23,6 +23,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/kubernetes/pkg/scheduler/algorithm/priorities"
 	schedulerapi "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 )
 
 func TestAlgorithmNameValidation(t *testing.T) {
@@ -98,3 +99,22 @@ func TestBuildScoringFunctionShapeFromRequestedToCapacityRatioArgumentsNilResour
 	assert.Equal(t, expectedShape, builtShape)
 	assert.Equal(t, expectedResources, resources)
 }
+
+func TestBuildScoringFunctionShapeFromRequestedToCapacityRatioArgumentsRtUtilization(t *testing.T) {
+	arguments := schedulerapi.RequestedToCapacityRatioArguments{
+		Shape: []schedulerapi.UtilizationShapePoint{
+			{Utilization: 10, Score: 1},
+			{Utilization: 70, Score: 2},
+		},
+		Resources: []schedulerapi.ResourceSpec{
+			{Name: string(v1.ResourceCPU)},
+			{Name: priorities.RtUtilizationWeightName, Weight: 5},
+		},
+	}
+	_, resources := buildScoringFunctionShapeFromRequestedToCapacityRatioArguments(&arguments)
+	expectedResources := priorities.ResourceToWeightMap{
+		v1.ResourceCPU:                          1,
+		schedulernodeinfo.ResourceRtUtilization: 5,
+	}
+	assert.Equal(t, expectedResources, resources)
+}
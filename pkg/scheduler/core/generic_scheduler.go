@@ -1164,7 +1164,22 @@ func (g *genericScheduler) selectVictimsOnNode(
 	}
 	var victims []*v1.Pod
 	numViolatingVictim := 0
-	sort.Slice(potentialVictims, func(i, j int) bool { return util.MoreImportantPod(potentialVictims[i], potentialVictims[j]) })
+	podRtUtil, _ := schedulernodeinfo.CalculatePodRtUtilAndCpu(pod)
+	sort.Slice(potentialVictims, func(i, j int) bool {
+		vi, vj := potentialVictims[i], potentialVictims[j]
+		// An RT pod only gains room when victims holding RT utilization go:
+		// among equally important victims, reprieve the ones without RT
+		// requests first, so the eviction frees RT headroom instead of cpu
+		// and memory the pod does not need.
+		if podRtUtil > 0 && podutil.GetPodPriority(vi) == podutil.GetPodPriority(vj) {
+			viRtUtil, _ := schedulernodeinfo.CalculatePodRtUtilAndCpu(vi)
+			vjRtUtil, _ := schedulernodeinfo.CalculatePodRtUtilAndCpu(vj)
+			if (viRtUtil == 0) != (vjRtUtil == 0) {
+				return viRtUtil == 0
+			}
+		}
+		return util.MoreImportantPod(vi, vj)
+	})
 	// Try to reprieve as many pods as possible. We first try to reprieve the PDB
 	// violating victims and then other non-violating ones. In both cases, we start
 	// from the highest priority victims.
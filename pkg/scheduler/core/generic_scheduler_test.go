@@ -1474,6 +1474,53 @@ func TestSelectNodesForPreemption(t *testing.T) {
 	}
 }
 
+// TestSelectNodesForPreemptionRtVictims verifies that when an RT pod drives
+// the preemption, the victim holding RT utilization is evicted while an
+// equally important non RT pod is reprieved.
+func TestSelectNodesForPreemptionRtVictims(t *testing.T) {
+	defer algorithmpredicates.SetPredicatesOrderingDuringTest(order)()
+
+	rtContainers := func(milliCPU, rtCpus, rtRuntime, rtPeriod int64) []v1.Container {
+		requests := v1.ResourceList{
+			v1.ResourceCPU:    *resource.NewMilliQuantity(milliCPU, resource.DecimalSI),
+			v1.ResourceMemory: *resource.NewQuantity(priorityutil.DefaultMemoryRequest, resource.BinarySI),
+		}
+		if rtCpus > 0 {
+			requests[v1.ResourceRtCpu] = *resource.NewQuantity(rtCpus, resource.DecimalSI)
+			requests[v1.ResourceRtRuntime] = *resource.NewQuantity(rtRuntime, resource.DecimalSI)
+			requests[v1.ResourceRtPeriod] = *resource.NewQuantity(rtPeriod, resource.DecimalSI)
+		}
+		return []v1.Container{{Resources: v1.ResourceRequirements{Requests: requests}}}
+	}
+
+	// 2000m of cpu and an RT utilization of 0.5 on each of the 2 cpus
+	node := makeNode("machine1", 2000, priorityutil.DefaultMemoryRequest*5)
+	node.Status.Allocatable[v1.ResourceRtPeriod] = *resource.NewQuantity(1000000, resource.DecimalSI)
+	node.Status.Allocatable[v1.ResourceRtRuntime] = *resource.NewQuantity(500000, resource.DecimalSI)
+
+	earlier := metav1.Date(2019, 1, 1, 1, 0, 0, 0, time.UTC)
+	later := metav1.Date(2019, 1, 2, 1, 0, 0, 0, time.UTC)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "new-rt", UID: types.UID("new-rt")}, Spec: v1.PodSpec{Containers: rtContainers(500, 1, 300000, 1000000), Priority: &highPriority}}
+	pods := []*v1.Pod{
+		// the RT victim started before the non RT pod, so on importance alone
+		// it would be reprieved first and the non RT pod evicted
+		{ObjectMeta: metav1.ObjectMeta{Name: "rt-victim", UID: types.UID("rt-victim")}, Spec: v1.PodSpec{Containers: rtContainers(800, 1, 400000, 1000000), Priority: &midPriority, NodeName: "machine1"}, Status: v1.PodStatus{StartTime: &earlier}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "non-rt", UID: types.UID("non-rt")}, Spec: v1.PodSpec{Containers: rtContainers(800, 0, 0, 0), Priority: &midPriority, NodeName: "machine1"}, Status: v1.PodStatus{StartTime: &later}},
+	}
+
+	g := makeScheduler(map[string]algorithmpredicates.FitPredicate{"matches": algorithmpredicates.PodFitsResources}, []*v1.Node{node})
+	g.nodeInfoSnapshot = nodeinfosnapshot.NewSnapshot(nodeinfosnapshot.CreateNodeInfoMap(pods, []*v1.Node{node}))
+
+	nodeToPods, err := g.selectNodesForPreemption(context.Background(), framework.NewCycleState(), pod, []*v1.Node{node}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]map[string]bool{"machine1": {"rt-victim": true}}
+	if err := checkPreemptionVictims(expected, nodeToPods); err != nil {
+		t.Error(err)
+	}
+}
+
 // TestPickOneNodeForPreemption tests pickOneNodeForPreemption.
 func TestPickOneNodeForPreemption(t *testing.T) {
 	defer algorithmpredicates.SetPredicatesOrderingDuringTest(order)()
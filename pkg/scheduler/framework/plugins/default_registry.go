@@ -38,6 +38,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/nodevolumelimits"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/podtopologyspread"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/requestedtocapacityratio"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/rtresources"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/serviceaffinity"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/tainttoleration"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/volumebinding"
@@ -70,6 +71,8 @@ func NewDefaultRegistry(args *RegistryArgs) framework.Registry {
 		noderesources.BalancedAllocationName: noderesources.NewBalancedAllocation,
 		noderesources.MostAllocatedName:      noderesources.NewMostAllocated,
 		noderesources.LeastAllocatedName:     noderesources.NewLeastAllocated,
+		rtresources.FitName:                  rtresources.NewFit,
+		rtresources.BalanceName:              rtresources.NewBalance,
 		volumebinding.Name: func(_ *runtime.Unknown, _ framework.FrameworkHandle) (framework.Plugin, error) {
 			return volumebinding.NewFromVolumeBinder(args.VolumeBinder), nil
 		},
@@ -42,6 +42,9 @@ func (f *Fit) Name() string {
 }
 
 // Filter invoked at the filter extension point.
+// It delegates to the PodFitsResources predicate, which next to the ordinary
+// compute resources also caps the RT utilization of real-time pods at a
+// safety fraction of the node allocatable RT bandwidth.
 func (f *Fit) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *nodeinfo.NodeInfo) *framework.Status {
 	meta, ok := migration.PredicateMetadata(cycleState).(predicates.Metadata)
 	if !ok {
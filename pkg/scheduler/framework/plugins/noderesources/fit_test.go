@@ -66,6 +66,17 @@ func makeAllocatableResources(milliCPU, memory, pods, extendedA, storage, hugePa
 	}
 }
 
+// makeRtAllocatableResources extends the allocatable resources of a node with
+// an RT bandwidth, cpu is given in whole cpus since the allocatable RT
+// utilization scales with their number.
+func makeRtAllocatableResources(cpus, rtRuntime, rtPeriod int64) v1.ResourceList {
+	rl := makeAllocatableResources(cpus*1000, 20, 32, 5, 20, 5)
+	rl[v1.ResourceCPU] = *resource.NewQuantity(cpus, resource.DecimalSI)
+	rl[v1.ResourceRtRuntime] = *resource.NewQuantity(rtRuntime, resource.DecimalSI)
+	rl[v1.ResourceRtPeriod] = *resource.NewQuantity(rtPeriod, resource.DecimalSI)
+	return rl
+}
+
 func newResourcePod(usage ...schedulernodeinfo.Resource) *v1.Pod {
 	containers := []v1.Container{}
 	for _, req := range usage {
@@ -466,3 +477,53 @@ func TestNodeResourcesFit(t *testing.T) {
 	}
 
 }
+
+func TestNodeResourcesFitRtUtilization(t *testing.T) {
+	// the node allocates 4 cpus with an RT bandwidth of 500000/1000000 each,
+	// a scaled allocatable utilization of 2000000 of which the predicate only
+	// hands out the RTSafetyUtilizationFactor fraction
+	rtUtilizationTests := []struct {
+		pod        *v1.Pod
+		nodeInfo   *schedulernodeinfo.NodeInfo
+		name       string
+		wantStatus *framework.Status
+	}{
+		{
+			pod: newResourcePod(schedulernodeinfo.Resource{RtUtil: 400000, RtCpu: 2}),
+			nodeInfo: schedulernodeinfo.NewNodeInfo(
+				newResourcePod(schedulernodeinfo.Resource{RtUtil: 300000, RtCpu: 2})),
+			name: "rt pod fits within the safe rt utilization",
+		},
+		{
+			pod: newResourcePod(schedulernodeinfo.Resource{RtUtil: 600000, RtCpu: 2}),
+			nodeInfo: schedulernodeinfo.NewNodeInfo(
+				newResourcePod(schedulernodeinfo.Resource{RtUtil: 300000, RtCpu: 2})),
+			name:       "rt pod does not fit beyond the safe rt utilization",
+			wantStatus: framework.NewStatus(framework.Unschedulable, predicates.NewInsufficientResourceError(schedulernodeinfo.ResourceRtUtilization, 1200000, 600000, 2000000).GetReason()),
+		},
+		{
+			pod: newResourcePod(schedulernodeinfo.Resource{MilliCPU: 1, Memory: 1}),
+			nodeInfo: schedulernodeinfo.NewNodeInfo(
+				newResourcePod(schedulernodeinfo.Resource{RtUtil: 300000, RtCpu: 2})),
+			name: "non-rt pod is not filtered by the rt utilization",
+		},
+	}
+
+	for _, test := range rtUtilizationTests {
+		t.Run(test.name, func(t *testing.T) {
+			factory := &predicates.MetadataProducerFactory{}
+			meta := factory.GetPredicateMetadata(test.pod, nil)
+			state := framework.NewCycleState()
+			state.Write(migration.PredicatesStateKey, &migration.PredicatesStateData{Reference: meta})
+
+			node := v1.Node{Status: v1.NodeStatus{Capacity: makeRtAllocatableResources(4, 500000, 1000000), Allocatable: makeRtAllocatableResources(4, 500000, 1000000)}}
+			test.nodeInfo.SetNode(&node)
+
+			p, _ := NewFit(nil, nil)
+			gotStatus := p.(framework.FilterPlugin).Filter(context.Background(), state, test.pod, test.nodeInfo)
+			if !reflect.DeepEqual(gotStatus, test.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, test.wantStatus)
+			}
+		})
+	}
+}
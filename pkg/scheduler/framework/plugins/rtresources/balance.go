@@ -0,0 +1,74 @@
+package rtresources
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/priorities"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// Balance is a score plugin that favors nodes with the most remaining
+// real-time utilization headroom, so that RT load spreads across nodes.
+type Balance struct {
+	handle framework.FrameworkHandle
+}
+
+var _ = framework.ScorePlugin(&Balance{})
+
+// BalanceName is the name of the plugin used in the plugin registry and configurations.
+const BalanceName = "RtResourceBalance"
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (b *Balance) Name() string {
+	return BalanceName
+}
+
+// Score invoked at the score extension point.
+// The score is the fraction of allocatable RT utilization that would remain
+// free on the node after placing the pod, normalized to the framework's
+// score range.
+func (b *Balance) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	nodeInfo, err := b.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
+	}
+
+	allocUtilization, reqUtilization := priorities.RTUtilizationAllocatableRequest(nodeInfo, pod)
+	if allocUtilization == 0 {
+		// either the pod has no RT requests or the node has no RT capacity,
+		// in both cases the node is as good as any other
+		return 0, nil
+	}
+
+	headroom := allocUtilization - reqUtilization
+	if headroom < 0 {
+		headroom = 0
+	}
+
+	return headroom * framework.MaxNodeScore / allocUtilization, nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (b *Balance) ScoreExtensions() framework.ScoreExtensions {
+	return b
+}
+
+// NormalizeScore invoked after scoring all nodes. The raw scores are
+// headroom fractions of each node taken in isolation; rescaling them against
+// the best node keeps the RT signal in the same effective range as the other
+// score plugins, so RT headroom does not dominate the total score.
+func (b *Balance) NormalizeScore(ctx context.Context, _ *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	// NormalizeReduce does not use the priority metadata or the lister.
+	if err := priorities.NormalizeReduce(framework.MaxNodeScore, false)(pod, nil, nil, scores); err != nil {
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	return nil
+}
+
+// NewBalance initializes a new plugin and returns it.
+func NewBalance(_ *runtime.Unknown, h framework.FrameworkHandle) (framework.Plugin, error) {
+	return &Balance{handle: h}, nil
+}
@@ -0,0 +1,141 @@
+package rtresources
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	nodeinfosnapshot "k8s.io/kubernetes/pkg/scheduler/nodeinfo/snapshot"
+)
+
+func TestRtResourceBalance(t *testing.T) {
+	tests := []struct {
+		name         string
+		pod          *v1.Pod
+		nodes        []*v1.Node
+		pods         []*v1.Pod
+		expectedList framework.NodeScoreList
+	}{
+		{
+			name: "non RT pod scores zero everywhere",
+			pod:  &v1.Pod{},
+			nodes: []*v1.Node{
+				makeRtNode("machine1", 950000, 1000000, 1),
+				makeRtNode("machine2", 950000, 1000000, 1),
+			},
+			expectedList: []framework.NodeScore{{Name: "machine1", Score: 0}, {Name: "machine2", Score: 0}},
+		},
+		{
+			name: "empty nodes keep full headroom",
+			pod:  newRtPod(95000, 1000000, 1),
+			nodes: []*v1.Node{
+				makeRtNode("machine1", 950000, 1000000, 1),
+				makeRtNode("machine2", 950000, 1000000, 1),
+			},
+			// 950000 allocatable, 95000 requested -> 90% headroom on both
+			expectedList: []framework.NodeScore{{Name: "machine1", Score: 90}, {Name: "machine2", Score: 90}},
+		},
+		{
+			name: "loaded node scores lower than free node",
+			pod:  newRtPod(95000, 1000000, 1),
+			nodes: []*v1.Node{
+				makeRtNode("machine1", 950000, 1000000, 1),
+				makeRtNode("machine2", 950000, 1000000, 1),
+			},
+			pods: []*v1.Pod{
+				{
+					Spec: v1.PodSpec{
+						NodeName: "machine1",
+						Containers: []v1.Container{
+							{
+								Resources: v1.ResourceRequirements{
+									Requests: makeRtResourceList(475000, 1000000, 1),
+								},
+							},
+						},
+					},
+				},
+			},
+			// machine1: (950000-475000-95000)/950000 = 40%, machine2 keeps 90%
+			expectedList: []framework.NodeScore{{Name: "machine1", Score: 40}, {Name: "machine2", Score: 90}},
+		},
+		{
+			name: "overloaded node scores zero",
+			pod:  newRtPod(950000, 1000000, 1),
+			nodes: []*v1.Node{
+				makeRtNode("machine1", 950000, 1000000, 1),
+			},
+			expectedList: []framework.NodeScore{{Name: "machine1", Score: 0}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snapshot := nodeinfosnapshot.NewSnapshot(nodeinfosnapshot.CreateNodeInfoMap(test.pods, test.nodes))
+			fh, _ := framework.NewFramework(nil, nil, nil, framework.WithSnapshotSharedLister(snapshot))
+			p, _ := NewBalance(nil, fh)
+
+			for i := range test.nodes {
+				score, status := p.(framework.ScorePlugin).Score(context.Background(), nil, test.pod, test.nodes[i].Name)
+				if !status.IsSuccess() {
+					t.Errorf("unexpected error: %v", status)
+				}
+				if score != test.expectedList[i].Score {
+					t.Errorf("node %s: expected score %d, got %d", test.nodes[i].Name, test.expectedList[i].Score, score)
+				}
+			}
+		})
+	}
+}
+
+func TestRtResourceBalanceNormalizeScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		scores   framework.NodeScoreList
+		expected framework.NodeScoreList
+	}{
+		{
+			name: "rescaled against the best node",
+			scores: framework.NodeScoreList{
+				{Name: "machine1", Score: 40},
+				{Name: "machine2", Score: 90},
+				{Name: "machine3", Score: 0},
+			},
+			expected: framework.NodeScoreList{
+				{Name: "machine1", Score: 40 * framework.MaxNodeScore / 90},
+				{Name: "machine2", Score: framework.MaxNodeScore},
+				{Name: "machine3", Score: 0},
+			},
+		},
+		{
+			name: "all zero scores are left alone",
+			scores: framework.NodeScoreList{
+				{Name: "machine1", Score: 0},
+				{Name: "machine2", Score: 0},
+			},
+			expected: framework.NodeScoreList{
+				{Name: "machine1", Score: 0},
+				{Name: "machine2", Score: 0},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fh, _ := framework.NewFramework(nil, nil, nil)
+			p, _ := NewBalance(nil, fh)
+			scorePlugin := p.(framework.ScorePlugin)
+
+			status := scorePlugin.ScoreExtensions().NormalizeScore(context.Background(), nil, &v1.Pod{}, test.scores)
+			if !status.IsSuccess() {
+				t.Fatalf("unexpected error: %v", status)
+			}
+			for i := range test.scores {
+				if test.scores[i].Score != test.expected[i].Score {
+					t.Errorf("node %s: expected normalized score %d, got %d", test.scores[i].Name, test.expected[i].Score, test.scores[i].Score)
+				}
+			}
+		})
+	}
+}
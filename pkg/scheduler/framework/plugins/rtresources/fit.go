@@ -0,0 +1,54 @@
+package rtresources
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// Fit is a plugin that checks if a node has enough real-time utilization
+// headroom for the pod's RT requests.
+type Fit struct{}
+
+var _ framework.FilterPlugin = &Fit{}
+
+// FitName is the name of the plugin used in the plugin registry and configurations.
+const FitName = "RtResourceFit"
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (f *Fit) Name() string {
+	return FitName
+}
+
+// Filter invoked at the filter extension point.
+// It filters out nodes whose allocatable RT utilization, scaled by the
+// safety factor, cannot accommodate the pod's requested RT utilization on
+// top of what is already requested on the node.
+func (f *Fit) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *schedulernodeinfo.NodeInfo) *framework.Status {
+	reqUtil, _ := schedulernodeinfo.CalculatePodRtUtilAndCpu(pod)
+	if reqUtil == 0 {
+		// non RT pods always fit
+		return nil
+	}
+
+	allocatable := nodeInfo.AllocatableResource()
+	requested := nodeInfo.RequestedResource()
+
+	if int64(predicates.RTSafetyUtilizationFactor*float64(allocatable.RtUtilization())) <
+		reqUtil+requested.RtUtilization() {
+		insufficient := predicates.NewInsufficientResourceError(schedulernodeinfo.ResourceRtUtilization,
+			reqUtil, requested.RtUtilization(), allocatable.RtUtilization())
+		return framework.NewStatus(framework.Unschedulable, insufficient.GetReason())
+	}
+
+	return nil
+}
+
+// NewFit initializes a new plugin and returns it.
+func NewFit(_ *runtime.Unknown, _ framework.FrameworkHandle) (framework.Plugin, error) {
+	return &Fit{}, nil
+}
@@ -0,0 +1,104 @@
+package rtresources
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func makeRtResourceList(runtime, period, cpus int64) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+		v1.ResourceRtPeriod:  *resource.NewQuantity(period, resource.DecimalSI),
+		v1.ResourceRtCpu:     *resource.NewQuantity(cpus, resource.DecimalSI),
+	}
+}
+
+func newRtPod(runtime, period, cpus int64) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: makeRtResourceList(runtime, period, cpus),
+					},
+				},
+			},
+		},
+	}
+}
+
+func makeRtNode(name string, runtime, period, cpus int64) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:       *resource.NewQuantity(cpus, resource.DecimalSI),
+				v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+				v1.ResourceRtPeriod:  *resource.NewQuantity(period, resource.DecimalSI),
+			},
+		},
+	}
+}
+
+func TestRtResourceFit(t *testing.T) {
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		node       *v1.Node
+		existing   []*v1.Pod
+		wantStatus *framework.Status
+	}{
+		{
+			name: "non RT pod always fits",
+			pod:  &v1.Pod{},
+			node: makeRtNode("machine1", 950000, 1000000, 1),
+		},
+		{
+			name: "RT pod fits on empty node",
+			pod:  newRtPod(100000, 1000000, 1),
+			node: makeRtNode("machine1", 950000, 1000000, 1),
+		},
+		{
+			name:     "RT pod fits next to an existing RT pod",
+			pod:      newRtPod(100000, 1000000, 1),
+			node:     makeRtNode("machine1", 950000, 1000000, 1),
+			existing: []*v1.Pod{newRtPod(100000, 1000000, 1)},
+		},
+		{
+			name: "RT pod exceeding the safe allocatable utilization does not fit",
+			pod:  newRtPod(900000, 1000000, 1),
+			node: makeRtNode("machine1", 950000, 1000000, 1),
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				predicates.NewInsufficientResourceError(schedulernodeinfo.ResourceRtUtilization, 900000, 0, 950000).GetReason()),
+		},
+		{
+			name:     "RT pod does not fit because of existing RT load",
+			pod:      newRtPod(400000, 1000000, 1),
+			node:     makeRtNode("machine1", 950000, 1000000, 1),
+			existing: []*v1.Pod{newRtPod(500000, 1000000, 1)},
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				predicates.NewInsufficientResourceError(schedulernodeinfo.ResourceRtUtilization, 400000, 500000, 950000).GetReason()),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodeInfo := schedulernodeinfo.NewNodeInfo(test.existing...)
+			nodeInfo.SetNode(test.node)
+
+			p, _ := NewFit(nil, nil)
+			gotStatus := p.(framework.FilterPlugin).Filter(context.Background(), nil, test.pod, nodeInfo)
+			if !reflect.DeepEqual(gotStatus, test.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, test.wantStatus)
+			}
+		})
+	}
+}
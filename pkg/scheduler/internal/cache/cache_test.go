@@ -228,6 +228,63 @@ func TestAssumePodScheduled(t *testing.T) {
 	}
 }
 
+func makeRtPod(nodeName, objName string, rtRuntime, rtPeriod, rtCpus int64) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(objName),
+			Namespace: "node_info_cache_test",
+			Name:      objName,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceRtRuntime: *resource.NewQuantity(rtRuntime, resource.DecimalSI),
+						v1.ResourceRtPeriod:  *resource.NewQuantity(rtPeriod, resource.DecimalSI),
+						v1.ResourceRtCpu:     *resource.NewQuantity(rtCpus, resource.DecimalSI),
+					},
+				},
+			}},
+			NodeName: nodeName,
+		},
+	}
+}
+
+// TestAssumeRtPodScheduled tests that the RT utilization of assumed pods
+// accumulates on the node, so concurrent scheduling cycles are checked
+// against the combined RT demand, and that forgetting a pod gives its
+// utilization back.
+func TestAssumeRtPodScheduled(t *testing.T) {
+	nodeName := "node"
+	testPods := []*v1.Pod{
+		// 0.1 utilization on 2 cpus and 0.25 utilization on 1 cpu
+		makeRtPod(nodeName, "test-rt-1", 100000, 1000000, 2),
+		makeRtPod(nodeName, "test-rt-2", 250000, 1000000, 1),
+	}
+
+	cache := newSchedulerCache(time.Second, time.Second, nil)
+	for _, pod := range testPods {
+		if err := cache.AssumePod(pod); err != nil {
+			t.Fatalf("AssumePod failed: %v", err)
+		}
+	}
+
+	// the per-cpu utilizations add up over the requested cpus:
+	// 0.1 * 2 + 0.25 * 1
+	wantRtUtil := int64(0.45 * schedulernodeinfo.RtUtilizationScale)
+	if got := cache.nodes[nodeName].info.RequestedResource().RtUtil; got != wantRtUtil {
+		t.Errorf("assumed RT utilization = %d, want %d", got, wantRtUtil)
+	}
+
+	if err := cache.ForgetPod(testPods[0]); err != nil {
+		t.Fatalf("ForgetPod failed: %v", err)
+	}
+	wantRtUtil = int64(0.25 * schedulernodeinfo.RtUtilizationScale)
+	if got := cache.nodes[nodeName].info.RequestedResource().RtUtil; got != wantRtUtil {
+		t.Errorf("RT utilization after forget = %d, want %d", got, wantRtUtil)
+	}
+}
+
 type testExpirePodStruct struct {
 	pod         *v1.Pod
 	assumedTime time.Time
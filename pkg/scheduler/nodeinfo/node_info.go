@@ -752,25 +752,48 @@ func (n *NodeInfo) Filter(pod *v1.Pod) bool {
 	return false
 }
 
-//
+// CalculatePodRtUtilAndCpu returns the scaled RT utilization and the RT cpus
+// requested by the pod. Unlike cpu and memory, RT utilization has no nonzero
+// default: containers without a complete RT request (both runtime and
+// period) contribute zero, so non RT pods never count against the RT
+// capacity of a node, in NonZeroRequest either.
+// Init containers follow the same max semantics as cpu and memory: they run
+// one at a time before the app containers, so the pod needs the largest of
+// any single init request and the sum of the app requests.
 func CalculatePodRtUtilAndCpu(pod *v1.Pod) (int64, int64) {
 	cpuSum := int64(0)
 	utilSum := int64(0)
 
 	for _, container := range pod.Spec.Containers {
-		rtPeriod := container.Resources.Requests.CpuRtPeriod().Value()
-		rtRuntime := container.Resources.Requests.CpuRtRuntime().Value()
-		rtCpus := container.Resources.Requests.CpuRt().Value()
-
-		if rtPeriod == 0 || rtRuntime == 0 {
-			continue
-		}
-
-		u := RtScaledUtilization(rtRuntime, rtPeriod, rtCpus)
+		u, rtCpus := containerRtUtilAndCpu(&container)
 		// TODO(stefano.fiori): be careful here we can overflow, check it out
 		utilSum += u
 		cpuSum += rtCpus
 	}
 
+	for _, container := range pod.Spec.InitContainers {
+		u, rtCpus := containerRtUtilAndCpu(&container)
+		if u > utilSum {
+			utilSum = u
+		}
+		if rtCpus > cpuSum {
+			cpuSum = rtCpus
+		}
+	}
+
 	return utilSum, cpuSum
 }
+
+// containerRtUtilAndCpu returns the scaled RT utilization and the RT cpus
+// requested by a single container, zero unless the RT request is complete.
+func containerRtUtilAndCpu(container *v1.Container) (int64, int64) {
+	rtPeriod := container.Resources.Requests.CpuRtPeriod().Value()
+	rtRuntime := container.Resources.Requests.CpuRtRuntime().Value()
+	rtCpus := container.Resources.Requests.CpuRt().Value()
+
+	if rtPeriod == 0 || rtRuntime == 0 {
+		return 0, 0
+	}
+
+	return RtScaledUtilization(rtRuntime, rtPeriod, rtCpus), rtCpus
+}
@@ -53,6 +53,7 @@ func TestNewResource(t *testing.T) {
 				MilliCPU:         4,
 				Memory:           2000,
 				EphemeralStorage: 5000,
+				RtCpu:            1,
 				AllowedPodNumber: 80,
 				ScalarResources:  map[v1.ResourceName]int64{"scalar.test/scalar1": 1, "hugepages-test": 2},
 			},
@@ -76,6 +77,9 @@ func TestResourceList(t *testing.T) {
 			resource: &Resource{},
 			expected: map[v1.ResourceName]resource.Quantity{
 				v1.ResourceCPU:              *resource.NewScaledQuantity(0, -3),
+				v1.ResourceRtPeriod:         *resource.NewQuantity(RtUtilizationScale, resource.DecimalSI),
+				v1.ResourceRtRuntime:        *resource.NewQuantity(0, resource.DecimalSI),
+				v1.ResourceRtCpu:            *resource.NewQuantity(0, resource.DecimalSI),
 				v1.ResourceMemory:           *resource.NewQuantity(0, resource.BinarySI),
 				v1.ResourcePods:             *resource.NewQuantity(0, resource.BinarySI),
 				v1.ResourceEphemeralStorage: *resource.NewQuantity(0, resource.BinarySI),
@@ -95,6 +99,9 @@ func TestResourceList(t *testing.T) {
 			},
 			expected: map[v1.ResourceName]resource.Quantity{
 				v1.ResourceCPU:                      *resource.NewScaledQuantity(4, -3),
+				v1.ResourceRtPeriod:                 *resource.NewQuantity(RtUtilizationScale, resource.DecimalSI),
+				v1.ResourceRtRuntime:                *resource.NewQuantity(0, resource.DecimalSI),
+				v1.ResourceRtCpu:                    *resource.NewQuantity(0, resource.DecimalSI),
 				v1.ResourceMemory:                   *resource.NewQuantity(2000, resource.BinarySI),
 				v1.ResourcePods:                     *resource.NewQuantity(80, resource.BinarySI),
 				v1.ResourceEphemeralStorage:         *resource.NewQuantity(5000, resource.BinarySI),
@@ -210,6 +217,7 @@ func TestSetMaxResource(t *testing.T) {
 				MilliCPU:         4,
 				Memory:           2000,
 				EphemeralStorage: 5000,
+				RtCpu:            1,
 			},
 		},
 		{
@@ -230,6 +238,7 @@ func TestSetMaxResource(t *testing.T) {
 				MilliCPU:         4,
 				Memory:           4000,
 				EphemeralStorage: 7000,
+				RtCpu:            1,
 				ScalarResources:  map[v1.ResourceName]int64{"scalar.test/scalar1": 4, "hugepages-test": 5},
 			},
 		},
@@ -1002,8 +1011,8 @@ func TestNodeInfo_AddPodRT(t *testing.T) {
 								{
 									Resources: v1.ResourceRequirements{
 										Requests: v1.ResourceList{
-											v1.ResourcePeriod:  *resource.NewQuantity(100000, resource.DecimalSI),
-											v1.ResourceRuntime: *resource.NewQuantity(11000, resource.DecimalSI),
+											v1.ResourceRtPeriod:  *resource.NewQuantity(100000, resource.DecimalSI),
+											v1.ResourceRtRuntime: *resource.NewQuantity(11000, resource.DecimalSI),
 										},
 									},
 								},
@@ -1022,8 +1031,8 @@ func TestNodeInfo_AddPodRT(t *testing.T) {
 								{
 									Resources: v1.ResourceRequirements{
 										Requests: v1.ResourceList{
-											v1.ResourcePeriod:  *resource.NewQuantity(190000, resource.DecimalSI),
-											v1.ResourceRuntime: *resource.NewQuantity(77000, resource.DecimalSI),
+											v1.ResourceRtPeriod:  *resource.NewQuantity(190000, resource.DecimalSI),
+											v1.ResourceRtRuntime: *resource.NewQuantity(77000, resource.DecimalSI),
 										},
 									},
 								},
@@ -1036,8 +1045,8 @@ func TestNodeInfo_AddPodRT(t *testing.T) {
 				MilliCPU:         0,
 				Memory:           0,
 				EphemeralStorage: 0,
-				RtRuntime:        979000,
-				RtPeriod:         1900000,
+				RtUtil:           RtScaledUtilization(11000, 100000, 0) + RtScaledUtilization(77000, 190000, 0),
+				RtCpu:            0,
 				AllowedPodNumber: 0,
 				ScalarResources:  nil,
 			},
@@ -1091,8 +1100,8 @@ func TestNodeInfo_RemovePodRT(t *testing.T) {
 								{
 									Resources: v1.ResourceRequirements{
 										Requests: v1.ResourceList{
-											v1.ResourcePeriod:  *resource.NewQuantity(100000, resource.DecimalSI),
-											v1.ResourceRuntime: *resource.NewQuantity(11000, resource.DecimalSI),
+											v1.ResourceRtPeriod:  *resource.NewQuantity(100000, resource.DecimalSI),
+											v1.ResourceRtRuntime: *resource.NewQuantity(11000, resource.DecimalSI),
 										},
 									},
 								},
@@ -1111,8 +1120,8 @@ func TestNodeInfo_RemovePodRT(t *testing.T) {
 								{
 									Resources: v1.ResourceRequirements{
 										Requests: v1.ResourceList{
-											v1.ResourcePeriod:  *resource.NewQuantity(123000, resource.DecimalSI),
-											v1.ResourceRuntime: *resource.NewQuantity(13000, resource.DecimalSI),
+											v1.ResourceRtPeriod:  *resource.NewQuantity(123000, resource.DecimalSI),
+											v1.ResourceRtRuntime: *resource.NewQuantity(13000, resource.DecimalSI),
 										},
 									},
 								},
@@ -1131,8 +1140,8 @@ func TestNodeInfo_RemovePodRT(t *testing.T) {
 								{
 									Resources: v1.ResourceRequirements{
 										Requests: v1.ResourceList{
-											v1.ResourcePeriod:  *resource.NewQuantity(190000, resource.DecimalSI),
-											v1.ResourceRuntime: *resource.NewQuantity(77000, resource.DecimalSI),
+											v1.ResourceRtPeriod:  *resource.NewQuantity(190000, resource.DecimalSI),
+											v1.ResourceRtRuntime: *resource.NewQuantity(77000, resource.DecimalSI),
 										},
 									},
 								},
@@ -1151,8 +1160,8 @@ func TestNodeInfo_RemovePodRT(t *testing.T) {
 				MilliCPU:         0,
 				Memory:           0,
 				EphemeralStorage: 0,
-				RtRuntime:        979000,
-				RtPeriod:         1900000,
+				RtUtil:           RtScaledUtilization(11000, 100000, 0) + RtScaledUtilization(77000, 190000, 0),
+				RtCpu:            0,
 				AllowedPodNumber: 0,
 				ScalarResources:  nil,
 			},
@@ -1162,12 +1171,16 @@ func TestNodeInfo_RemovePodRT(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ni := fakeNodeInfo(tt.fields.pods...)
 
-			fakePod := &v1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					UID: tt.args.podUID,
-				}}
+			// RemovePod subtracts the requests of the pod it is handed, so
+			// the stored pod is looked up instead of faked from the uid
+			var podToRemove *v1.Pod
+			for _, pod := range tt.fields.pods {
+				if pod.UID == tt.args.podUID {
+					podToRemove = pod
+				}
+			}
 
-			if err := ni.RemovePod(fakePod); (err != nil) != tt.wantErr {
+			if err := ni.RemovePod(podToRemove); (err != nil) != tt.wantErr {
 				t.Errorf("RemovePod() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -1178,3 +1191,138 @@ func TestNodeInfo_RemovePodRT(t *testing.T) {
 		})
 	}
 }
+
+func TestNonZeroRequestRtUtilization(t *testing.T) {
+	makePod := func(name string, requests v1.ResourceList) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Resources: v1.ResourceRequirements{Requests: requests}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		requests   v1.ResourceList
+		wantRtUtil int64
+	}{
+		{
+			// cpu and memory fall back to nonzero defaults, RT utilization
+			// must not
+			name:       "no requests at all",
+			requests:   v1.ResourceList{},
+			wantRtUtil: 0,
+		},
+		{
+			name: "rt runtime without period",
+			requests: v1.ResourceList{
+				v1.ResourceRtRuntime: resource.MustParse("100000"),
+			},
+			wantRtUtil: 0,
+		},
+		{
+			name: "complete rt request",
+			requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  resource.MustParse("1000000"),
+				v1.ResourceRtRuntime: resource.MustParse("100000"),
+				v1.ResourceRtCpu:     resource.MustParse("2"),
+			},
+			wantRtUtil: RtScaledUtilization(100000, 1000000, 2),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ni := NewNodeInfo()
+			ni.AddPod(makePod("pod", test.requests))
+			if got := ni.NonZeroRequest().RtUtilization(); got != test.wantRtUtil {
+				t.Errorf("NonZeroRequest().RtUtilization() = %d, want %d", got, test.wantRtUtil)
+			}
+			if ni.NonZeroRequest().MilliCPU == 0 {
+				t.Error("NonZeroRequest().MilliCPU = 0, the nonzero cpu default did not apply")
+			}
+		})
+	}
+}
+
+func TestCalculatePodRtUtilAndCpuInitContainers(t *testing.T) {
+	rtRequests := func(runtime, period, cpus string) v1.ResourceRequirements {
+		return v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceRtPeriod:  resource.MustParse(period),
+				v1.ResourceRtRuntime: resource.MustParse(runtime),
+				v1.ResourceRtCpu:     resource.MustParse(cpus),
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		containers     []v1.Container
+		initContainers []v1.Container
+		wantUtil       int64
+		wantCpu        int64
+	}{
+		{
+			name:       "init requesting more RT than the app containers",
+			containers: []v1.Container{{Resources: rtRequests("100000", "1000000", "1")}},
+			initContainers: []v1.Container{
+				{Resources: rtRequests("500000", "1000000", "2")},
+			},
+			wantUtil: RtScaledUtilization(500000, 1000000, 2),
+			wantCpu:  2,
+		},
+		{
+			name: "app containers sum above every init container",
+			containers: []v1.Container{
+				{Resources: rtRequests("300000", "1000000", "1")},
+				{Resources: rtRequests("300000", "1000000", "1")},
+			},
+			initContainers: []v1.Container{
+				{Resources: rtRequests("400000", "1000000", "1")},
+			},
+			wantUtil: 2 * RtScaledUtilization(300000, 1000000, 1),
+			wantCpu:  2,
+		},
+		{
+			name: "non RT init containers do not count",
+			containers: []v1.Container{
+				{Resources: rtRequests("100000", "1000000", "1")},
+			},
+			initContainers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+					v1.ResourceRtRuntime: resource.MustParse("900000"),
+				}}},
+			},
+			wantUtil: RtScaledUtilization(100000, 1000000, 1),
+			wantCpu:  1,
+		},
+		{
+			name: "RT init containers in a non RT pod",
+			containers: []v1.Container{
+				{},
+			},
+			initContainers: []v1.Container{
+				{Resources: rtRequests("200000", "1000000", "1")},
+			},
+			wantUtil: RtScaledUtilization(200000, 1000000, 1),
+			wantCpu:  1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers:     test.containers,
+					InitContainers: test.initContainers,
+				},
+			}
+			util, cpu := CalculatePodRtUtilAndCpu(pod)
+			if util != test.wantUtil || cpu != test.wantCpu {
+				t.Errorf("CalculatePodRtUtilAndCpu() = (%d, %d), want (%d, %d)", util, cpu, test.wantUtil, test.wantCpu)
+			}
+		})
+	}
+}
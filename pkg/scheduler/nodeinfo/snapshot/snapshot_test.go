@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
@@ -175,3 +176,55 @@ func TestCreateImageExistenceMap(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateNodeInfoMapRtUtilization(t *testing.T) {
+	rtResources := func(runtime, period, cpus int64) v1.ResourceList {
+		return v1.ResourceList{
+			v1.ResourceRtRuntime: *resource.NewQuantity(runtime, resource.DecimalSI),
+			v1.ResourceRtPeriod:  *resource.NewQuantity(period, resource.DecimalSI),
+			v1.ResourceRtCpu:     *resource.NewQuantity(cpus, resource.DecimalSI),
+		}
+	}
+	pods := []*v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "rt-pod-1", Namespace: "ns", UID: "1"},
+			Spec: v1.PodSpec{
+				NodeName: "node-0",
+				Containers: []v1.Container{
+					{Resources: v1.ResourceRequirements{Requests: rtResources(100000, 1000000, 2)}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "rt-pod-2", Namespace: "ns", UID: "2"},
+			Spec: v1.PodSpec{
+				NodeName: "node-0",
+				Containers: []v1.Container{
+					{Resources: v1.ResourceRequirements{Requests: rtResources(500000, 1000000, 1)}},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "non-rt-pod", Namespace: "ns", UID: "3"},
+			Spec: v1.PodSpec{
+				NodeName: "node-0",
+				Containers: []v1.Container{{}},
+			},
+		},
+	}
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}},
+	}
+
+	nodeInfoMap := CreateNodeInfoMap(pods, nodes)
+
+	nodeInfo, ok := nodeInfoMap["node-0"]
+	if !ok {
+		t.Fatal("no NodeInfo for node-0")
+	}
+	// 2 cpus at 0.1 plus 1 cpu at 0.5, in RtUtilizationScale units
+	want := int64(0.7 * schedulernodeinfo.RtUtilizationScale)
+	if got := nodeInfo.RequestedResource().RtUtil; got != want {
+		t.Errorf("requested RT utilization = %d, want %d", got, want)
+	}
+}
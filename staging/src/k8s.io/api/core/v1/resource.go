@@ -57,6 +57,17 @@ func (self *ResourceList) CpuRt() *resource.Quantity {
 	return &resource.Quantity{Format: resource.DecimalSI}
 }
 
+// Returns the per-cpu RT utilization, the ratio of the rt runtime over the
+// rt period. It is 0 when the period is missing or zero, so incomplete RT
+// requests never divide by zero.
+func (self *ResourceList) CpuRtUtilization() float64 {
+	period := self.CpuRtPeriod().Value()
+	if period == 0 {
+		return 0
+	}
+	return float64(self.CpuRtRuntime().Value()) / float64(period)
+}
+
 // Returns the Memory limit if specified.
 func (self *ResourceList) Memory() *resource.Quantity {
 	if val, ok := (*self)[ResourceMemory]; ok {
@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourceListCpuRtUtilization(t *testing.T) {
+	testCases := []struct {
+		name     string
+		list     ResourceList
+		expected float64
+	}{
+		{
+			name:     "empty list",
+			list:     ResourceList{},
+			expected: 0,
+		},
+		{
+			name: "runtime without period",
+			list: ResourceList{
+				ResourceRtRuntime: resource.MustParse("100000"),
+			},
+			expected: 0,
+		},
+		{
+			name: "period without runtime",
+			list: ResourceList{
+				ResourceRtPeriod: resource.MustParse("1000000"),
+			},
+			expected: 0,
+		},
+		{
+			name: "complete rt request",
+			list: ResourceList{
+				ResourceRtPeriod:  resource.MustParse("1000000"),
+				ResourceRtRuntime: resource.MustParse("250000"),
+			},
+			expected: 0.25,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := testCase.list.CpuRtUtilization(); got != testCase.expected {
+				t.Errorf("expected: %f, got: %f", testCase.expected, got)
+			}
+		})
+	}
+}
@@ -1694,6 +1694,16 @@ func describeContainerResource(container corev1.Container, w PrefixWriter) {
 		quantity := resources.Requests[name]
 		w.Write(LEVEL_3, "%s:\t%s\n", name, quantity.String())
 	}
+
+	// real-time requests additionally show the utilization they translate
+	// to, runtime over period summed across the requested cpus
+	if util := resources.Requests.CpuRtUtilization(); util > 0 {
+		cpus := resources.Requests.CpuRt().Value()
+		if cpus == 0 {
+			cpus = 1
+		}
+		w.Write(LEVEL_2, "RT Utilization:\t%.3f\n", util*float64(cpus))
+	}
 }
 
 func describeContainerState(status corev1.ContainerStatus, w PrefixWriter) {
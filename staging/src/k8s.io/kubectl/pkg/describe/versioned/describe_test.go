@@ -779,6 +779,21 @@ func TestDescribeContainers(t *testing.T) {
 			},
 			expectedElements: []string{"cpu", "1k", "memory", "4G", "storage", "20G"},
 		},
+		// Using real-time requests.
+		{
+			container: corev1.Container{
+				Name:  "test",
+				Image: "image",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceName(corev1.ResourceRtPeriod):  resource.MustParse("1000000"),
+						corev1.ResourceName(corev1.ResourceRtRuntime): resource.MustParse("250000"),
+						corev1.ResourceName(corev1.ResourceRtCpu):     resource.MustParse("2"),
+					},
+				},
+			},
+			expectedElements: []string{"rt_period", "1M", "rt_runtime", "250k", "rt_cpu", "2", "RT Utilization", "0.500"},
+		},
 		// volumeMounts read/write
 		{
 			container: corev1.Container{
@@ -516,6 +516,16 @@ type KubeletConfiguration struct {
 	// CPU resources is reallocated. Applicable to real-time scheduling tasks only. cpu.rt_period_us
 	// +optional
 	RTPeriod *metav1.Duration `json:"rtPeriod,omitempty"`
+	// RTAggressiveReclaim makes the cpu manager reclaim the RT utilization of
+	// containers confirmed gone from the runtime even while other pods'
+	// statuses are still incomplete.
+	// +optional
+	RTAggressiveReclaim *bool `json:"rtAggressiveReclaim,omitempty"`
+	// RTSerializeStartup makes the cpu manager serialize the RT container
+	// starts racing at node startup, ordered by descending RT utilization,
+	// so placement after a reboot fragments the cpus as little as possible.
+	// +optional
+	RTSerializeStartup *bool `json:"rtSerializeStartup,omitempty"`
 	// maxOpenFiles is Number of files that can be opened by Kubelet process.
 	// Dynamic Kubelet Config (beta): If dynamically updating this field, consider that
 	// it may impact the ability of the Kubelet to interact with the node's filesystem.
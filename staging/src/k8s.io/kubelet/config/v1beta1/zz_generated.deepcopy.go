@@ -200,6 +200,16 @@ func (in *KubeletConfiguration) DeepCopyInto(out *KubeletConfiguration) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.RTAggressiveReclaim != nil {
+		in, out := &in.RTAggressiveReclaim, &out.RTAggressiveReclaim
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RTSerializeStartup != nil {
+		in, out := &in.RTSerializeStartup, &out.RTSerializeStartup
+		*out = new(bool)
+		**out = **in
+	}
 	if in.KubeAPIQPS != nil {
 		in, out := &in.KubeAPIQPS, &out.KubeAPIQPS
 		*out = new(int32)